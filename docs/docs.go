@@ -23,9 +23,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/wa/logout": {
-            "post": {
-                "description": "Logout Whatsapp Account",
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the api calls newest first with the key that made them",
                 "consumes": [
                     "application/json"
                 ],
@@ -33,18 +38,46 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Whatsapp"
+                    "Auth"
+                ],
+                "summary": "List audit log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "filter by api key id",
+                        "name": "key_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Logout Whatsapp Account",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                            "$ref": "#/definitions/handlers.AuditLogResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/utils.MessageResponseError"
                         }
@@ -58,9 +91,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/wa/messages": {
-            "post": {
-                "description": "Send messages custom to whatsapp",
+        "/contacts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the contacts of the directory ordered by name",
                 "consumes": [
                     "application/json"
                 ],
@@ -68,29 +106,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "News"
+                    "Contacts"
                 ],
-                "summary": "Send messages custom to whatsapp",
+                "summary": "List contacts",
                 "parameters": [
                     {
-                        "description": "body request detail",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.WhatsappMessagesReq"
-                        }
+                        "type": "string",
+                        "description": "filter by tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search in the name and number",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "items per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                            "$ref": "#/definitions/handlers.ContactsResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/utils.MessageResponseError"
                         }
@@ -102,11 +163,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/wa/news": {
+            },
             "post": {
-                "description": "Send news to whatsapp",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a contact to the directory, send endpoints can then target it with contact_ids or one of its tags",
                 "consumes": [
                     "application/json"
                 ],
@@ -114,9 +178,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "News"
+                    "Contacts"
                 ],
-                "summary": "Send news to whatsapp",
+                "summary": "Create contact",
                 "parameters": [
                     {
                         "description": "body request detail",
@@ -124,15 +188,15 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.NewsSendWhatsappReq"
+                            "$ref": "#/definitions/models.ContactReq"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                            "$ref": "#/definitions/handlers.ContactResponse"
                         }
                     },
                     "400": {
@@ -141,6 +205,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/utils.MessageResponseError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -150,9 +232,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/wa/status": {
+        "/contacts/{id}": {
             "get": {
-                "description": "Check Whatsapp Status",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get one contact of the directory",
                 "consumes": [
                     "application/json"
                 ],
@@ -160,14 +247,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Whatsapp"
+                    "Contacts"
+                ],
+                "summary": "Get contact",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "contact id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Check Whatsapp Status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.WAStatusResponse"
+                            "$ref": "#/definitions/handlers.ContactResponse"
                         }
                     },
                     "400": {
@@ -176,6 +272,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/utils.MessageResponseError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -183,11 +297,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/wa/weathers": {
-            "post": {
-                "description": "Send weather daily forecast",
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace a contact of the directory",
                 "consumes": [
                     "application/json"
                 ],
@@ -195,17 +312,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "News"
+                    "Contacts"
                 ],
-                "summary": "Send weather daily forecast to whatsapp",
+                "summary": "Update contact",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "contact id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "description": "body request detail",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.WeatherSendWhatsappReq"
+                            "$ref": "#/definitions/models.ContactReq"
                         }
                     }
                 ],
@@ -213,7 +337,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                            "$ref": "#/definitions/handlers.ContactResponse"
                         }
                     },
                     "400": {
@@ -222,6 +346,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/utils.MessageResponseError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -229,87 +377,5069 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "handlers.WAStatusResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "type": "object",
-                    "properties": {
-                        "is_connected": {
-                            "type": "boolean"
-                        },
-                        "is_ready": {
-                            "type": "boolean"
-                        },
-                        "qr_code": {
-                            "type": "string"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a contact from the directory",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contacts"
+                ],
+                "summary": "Delete contact",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "contact id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
                         }
                     }
-                },
-                "error": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "message": {
-                    "type": "string"
                 }
             }
         },
-        "models.NewsSendWhatsappReq": {
-            "type": "object",
-            "properties": {
-                "category": {
-                    "description": "options: business, entertainment, general, health, science, sports, technology",
-                    "type": "string",
-                    "example": "business"
-                },
-                "using_llm": {
-                    "description": "options: true, false, if set to true, the message news will be add with llm and if false, the message news will be add with the default message",
-                    "type": "boolean",
-                    "example": true
-                },
-                "whatsapp_numbers": {
-                    "description": "list of numbers to send the news to and start with code number like 62 and not 0 like 08123456789",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        "/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the progress of an async broadcast, the pipeline stage and the latest outcome of every recipient",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get async job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.JobResponse"
+                        }
                     },
-                    "example": [
-                        "6285727771234",
-                        "6285667889887"
-                    ]
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
                 }
             }
         },
-        "models.WeatherSendWhatsappReq": {
+        "/keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List every api key newest first, revoked keys included. The key values are never returned",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "List api keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIKeysResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issue a new api key with the scopes, the key value is only in this response so store it right away",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Issue api key",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.IssuedAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke an api key, it can not be used anymore but stays in the list and the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Revoke api key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "api key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List every outbound message newest first with its origin and delivery state, the state moves from sent to delivered, read and played with the receipts of the recipient",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messages"
+                ],
+                "summary": "List message history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "filter by whatsapp session",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by recipient number or group JID",
+                        "name": "number",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "news",
+                            "weather",
+                            "custom",
+                            "media",
+                            "reply"
+                        ],
+                        "type": "string",
+                        "description": "filter by broadcast type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "sending",
+                            "sent",
+                            "delivered",
+                            "read",
+                            "played",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created at or after, a date like 2025-04-01 or a RFC3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created before, a plain date includes the whole day",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.MessagesPageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download every message that matches the filters as CSV or JSON Lines, newest first",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Messages"
+                ],
+                "summary": "Export message history",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by whatsapp session",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by recipient number or group JID",
+                        "name": "number",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "news",
+                            "weather",
+                            "custom",
+                            "media",
+                            "reply"
+                        ],
+                        "type": "string",
+                        "description": "filter by broadcast type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "sending",
+                            "sent",
+                            "delivered",
+                            "read",
+                            "played",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created at or after, a date like 2025-04-01 or a RFC3339 time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created before, a plain date includes the whole day",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get one sent message with its origin, delivery state and the time of every receipt, the id is the outbox_id of the send result",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Messages"
+                ],
+                "summary": "Get message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OutboxMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/news/sources": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the NewsAPI sources, their ids can be used as sources of /wa/news, /wa/news/search and the watchlists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "News"
+                ],
+                "summary": "List news sources",
+                "parameters": [
+                    {
+                        "enum": [
+                            "business",
+                            "entertainment",
+                            "general",
+                            "health",
+                            "science",
+                            "sports",
+                            "technology"
+                        ],
+                        "type": "string",
+                        "description": "category of the sources",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 639-1 code of the language of the sources, like en",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 3166-1 code of the country of the sources, like us or id",
+                        "name": "country",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.NewsSourcesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/outbox": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List outbound messages newest first, use status=dead to inspect the dead-letter messages",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbox"
+                ],
+                "summary": "List outbox messages",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "sending",
+                            "sent",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by whatsapp session",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OutboxMessagesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/outbox/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get one outbound message with its attempts and last error",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbox"
+                ],
+                "summary": "Get outbox message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "outbox message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OutboxMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/outbox/{id}/replay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Put a dead-letter message back in the outbox with a fresh set of attempts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Outbox"
+                ],
+                "summary": "Replay dead message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "outbox message id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.OutboxMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List every recurring broadcast with its next run time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "List schedules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SchedulesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a recurring news, weather, custom message or RSS/Atom feed broadcast",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Create schedule",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduleReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get one recurring broadcast with its next run time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Get schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "schedule id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace a recurring broadcast, the new version is used from its next run",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Update schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "schedule id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScheduleReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a recurring broadcast together with its run log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Delete schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "schedule id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/runs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the run log of a schedule newest first, with the outcome of every recipient",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "List schedule runs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "schedule id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScheduleRunsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the subscriptions recipients made themselves with the bot commands like !subscribe news technology",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscriptions"
+                ],
+                "summary": "List subscriptions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "filter by whatsapp session",
+                        "name": "session",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by number",
+                        "name": "number",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "news",
+                            "weather"
+                        ],
+                        "type": "string",
+                        "description": "filter by topic",
+                        "name": "topic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SubscriptionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a subscription on behalf of the recipient",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscriptions"
+                ],
+                "summary": "Delete subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "subscription id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/check-numbers": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Normalize the numbers to the international format and check if they are registered on WhatsApp, numbers starting with 0 get the DEFAULT_COUNTRY_CODE. Answers are cached so checking the same numbers again is cheap",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "Check whatsapp numbers",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WhatsappCheckNumbersReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WACheckNumbersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream of the session. It starts with a status event and then pushes qr, paired, connected, disconnected and logged_out as they happen. The QR code is only sent to admin keys and the web dashboard login",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "Stream whatsapp connection events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "whatsapp session, the default session when empty",
+                        "name": "session",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/whatsapp.Event"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/feeds": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Read an RSS 2.0 or Atom feed, for outlets that are not in NewsAPI, and send its latest items with the same formatting, rich and llm options as /wa/news. With async set to true it answers 202 with a job id to poll at /jobs/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "News"
+                ],
+                "summary": "Send feed to whatsapp",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FeedSendWhatsappReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/groups": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the WhatsApp groups the account is a member of, the jid can be used in whatsapp_numbers of every send endpoint",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "List joined groups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "whatsapp session, the default session when empty",
+                        "name": "session",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WAGroupsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/groups/join": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Join a WhatsApp group with its invite link, groups that need admin approval are joined once approved",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "Join group from invite link",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WhatsappJoinGroupReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WAGroupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/logout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Logout Whatsapp Account, the session stays and starts a new QR login",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "Logout Whatsapp Account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "whatsapp session, the default session when empty",
+                        "name": "session",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/media": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Send an image, document, audio or video to whatsapp, from an uploaded file or fetched from a url. With async set to true it answers 202 with a job id to poll at /jobs/{id}",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "News"
+                ],
+                "summary": "Send media to whatsapp",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated numbers with the country code, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us",
+                        "name": "whatsapp_numbers",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "media file, required when url is empty",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "url to fetch the media from, required when file is empty",
+                        "name": "url",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "image",
+                            "document",
+                            "audio",
+                            "video"
+                        ],
+                        "type": "string",
+                        "description": "media type, guessed from the mimetype when empty",
+                        "name": "type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "caption of the media, not shown for audio",
+                        "name": "caption",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "whatsapp session to send from, the default session when empty",
+                        "name": "session",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "show recording or typing in the chat before the media is sent",
+                        "name": "typing",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "skip numbers that are invalid or not on WhatsApp instead of rejecting the request",
+                        "name": "skip_invalid",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in the background and answer with a job id",
+                        "name": "async",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/messages": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Send messages custom to whatsapp, with async set to true it answers 202 with a job id to poll at /jobs/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "News"
+                ],
+                "summary": "Send messages custom to whatsapp",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WhatsappMessagesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/news": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Send the top headlines of a category, country or sources to whatsapp. They come from NewsAPI, or from the fallback feeds of the category while NewsAPI is rate limited or down. With rich set to true every headline is sent as an image message with one result per number and message. With async set to true it answers 202 with a job id to poll at /jobs/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "News"
+                ],
+                "summary": "Send news to whatsapp",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NewsSendWhatsappReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/news/search": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Search every NewsAPI article for the keywords, like a company name or its competitors, and send the newest matches with the same formatting, rich and llm options as /wa/news. With async set to true it answers 202 with a job id to poll at /jobs/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "News"
+                ],
+                "summary": "Send news search to whatsapp",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NewsSearchSendWhatsappReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/pair": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Link the session without scanning the QR code. WhatsApp notifies the phone of the number, then enter the returned code from Linked devices \u003e Link with phone number instead. The code expires after a few minutes, ask a new one when it does",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "Pair whatsapp with a phone number",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WhatsappPairPhoneReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WAPairCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List every WhatsApp account of the server with its connection state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "List whatsapp sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASessionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new WhatsApp session and start its QR login, get the QR code from /wa/sessions/{name}/pair",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Create whatsapp session",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WhatsappSessionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASessionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/sessions/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get one WhatsApp session with its connection state",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get whatsapp session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASessionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Log the session out, remove its device and delete it. The default session can only be logged out",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Delete whatsapp session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/sessions/{name}/pair": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Start the session if needed and return its current QR code, scan it from WhatsApp \u003e Linked devices. Call it again to get the rotated code until is_logged_in is true",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Pair whatsapp session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASessionPairResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Check Whatsapp Status, the QR code to pair the session is only returned to admin keys and the web dashboard login",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Whatsapp"
+                ],
+                "summary": "Check Whatsapp Status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "whatsapp session, the default session when empty",
+                        "name": "session",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WAStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/wa/weathers": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Send weather daily forecast, with async set to true it answers 202 with a job id to poll at /jobs/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "News"
+                ],
+                "summary": "Send weather daily forecast to whatsapp",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WeatherSendWhatsappReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WASendResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List every keyword watchlist with the time of its last and next poll",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlists"
+                ],
+                "summary": "List watchlists",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WatchlistsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a NewsAPI everything search that is polled on its interval, only articles it has not seen before are sent as a WhatsApp alert. The first poll runs within a minute and only remembers the articles already published",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlists"
+                ],
+                "summary": "Create watchlist",
+                "parameters": [
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchlistReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WatchlistResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get one keyword watchlist with the time of its last and next poll",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlists"
+                ],
+                "summary": "Get watchlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "watchlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WatchlistResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace a keyword watchlist. When the search changes the next poll runs right away and only remembers the articles of the new search",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlists"
+                ],
+                "summary": "Update watchlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "watchlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "body request detail",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WatchlistReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WatchlistResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a keyword watchlist together with its seen articles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlists"
+                ],
+                "summary": "Delete watchlist",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "watchlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists/{id}/articles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the articles a watchlist has found, the latest first. Articles of the first poll are remembered without an alert",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlists"
+                ],
+                "summary": "List watchlist articles",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "watchlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WatchlistArticlesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/watchlists/{id}/poll": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Poll a watchlist right away instead of waiting for its interval, new articles are alerted like a regular poll and the next poll moves one interval ahead",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlists"
+                ],
+                "summary": "Poll watchlist now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "watchlist id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.WatchlistPollResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.MessageResponseError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "reporting"
+                },
+                "prefix": {
+                    "description": "start of the key to recognize it",
+                    "type": "string",
+                    "example": "wan_3f9a1c"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "send",
+                        "status"
+                    ]
+                }
+            }
+        },
+        "auth.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "10.0.0.12"
+                },
+                "key_id": {
+                    "description": "0 for the web dashboard login",
+                    "type": "integer",
+                    "example": 1
+                },
+                "key_name": {
+                    "description": "name of the key or web:\u003cusername\u003e for the web dashboard login",
+                    "type": "string",
+                    "example": "reporting"
+                },
+                "method": {
+                    "type": "string",
+                    "example": "POST"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/api/wa/news"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "b2c4f3a0-1c7e-4b6e-9a55-5f0e2c1d8a11"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "contacts.Contact": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "language": {
+                    "description": "preferred language, ISO 639-1 code",
+                    "type": "string",
+                    "example": "id"
+                },
+                "lat": {
+                    "description": "default location, used for the weather",
+                    "type": "number",
+                    "example": -6.2617
+                },
+                "lon": {
+                    "type": "number",
+                    "example": 106.8103
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Budi Santoso"
+                },
+                "number": {
+                    "description": "normalized number or group JID",
+                    "type": "string",
+                    "example": "6285727771234"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "team",
+                        "jakarta"
+                    ]
+                },
+                "timezone": {
+                    "description": "IANA timezone of the contact",
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/auth.APIKey"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.APIKeysResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.APIKey"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.AuditLogResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.AuditEntry"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ContactResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/contacts.Contact"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ContactsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/contacts.Contact"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.IssuedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "key": {
+                    "type": "string",
+                    "example": "wan_3f9a1c0d6e2b4f8a9c1d2e3f4a5b6c7d8e9f0a1b2c3d4e5f"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "reporting"
+                },
+                "prefix": {
+                    "description": "start of the key to recognize it",
+                    "type": "string",
+                    "example": "wan_3f9a1c"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "send",
+                        "status"
+                    ]
+                }
+            }
+        },
+        "handlers.IssuedAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handlers.IssuedAPIKey"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.JobResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/jobs.Job"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.MessagesPage": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/outbox.Message"
+                    }
+                },
+                "next_cursor": {
+                    "description": "empty on the last page",
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "handlers.MessagesPageResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/handlers.MessagesPage"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.NewsSourcesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/newsapi.Source"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.OutboxMessageResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/outbox.Message"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.OutboxMessagesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/outbox.Message"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ScheduleResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/scheduler.Schedule"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ScheduleRunsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.Run"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SchedulesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scheduler.Schedule"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.SubscriptionsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/subscriptions.Subscription"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WACheckNumbersResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WhatsappNumberCheck"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WAGroupResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.WhatsappGroup"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WAGroupsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WhatsappGroup"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WAPairCodeResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.WhatsappPairCode"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WASendResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WhatsappSendResult"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WASessionPairResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.WhatsappSessionPair"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WASessionResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/models.WhatsappSession"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WASessionsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WhatsappSession"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WAStatusResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object",
+                    "properties": {
+                        "is_connected": {
+                            "type": "boolean"
+                        },
+                        "is_ready": {
+                            "type": "boolean"
+                        },
+                        "qr_code": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WatchlistArticlesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/watchlists.Article"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WatchlistPollResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/watchlists.PollResult"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WatchlistResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/watchlists.Watchlist"
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.WatchlistsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/watchlists.Watchlist"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "jobs.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b6c1f2e-4f43-4d3c-9a55-0c54a5f2a1d7"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WhatsappSendResult"
+                    }
+                },
+                "stage": {
+                    "description": "options: queued, fetching, llm, sending, done",
+                    "type": "string",
+                    "example": "sending"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "type": {
+                    "description": "options: news, news_search, feed, weather, messages, media",
+                    "type": "string",
+                    "example": "news"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.APIKeyReq": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "required, name to recognize the key in the audit log",
+                    "type": "string",
+                    "example": "reporting"
+                },
+                "scopes": {
+                    "description": "required, options: send, status, logout, admin",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "send",
+                        "status"
+                    ]
+                }
+            }
+        },
+        "models.ContactReq": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "description": "preferred language, ISO 639-1 code like id or en",
+                    "type": "string",
+                    "example": "id"
+                },
+                "lat": {
+                    "description": "latitude of the default location, the weather broadcasts use it instead of the requested location",
+                    "type": "number",
+                    "example": -6.2617
+                },
+                "lon": {
+                    "description": "longitude of the default location, required with lat",
+                    "type": "number",
+                    "example": 106.8103
+                },
+                "name": {
+                    "description": "required, name to recognize the contact",
+                    "type": "string",
+                    "example": "Budi Santoso"
+                },
+                "number": {
+                    "description": "required, number with the country code, a leading 0 gets the DEFAULT_COUNTRY_CODE, or a group JID like 120363025246125486@g.us",
+                    "type": "string",
+                    "example": "085727771234"
+                },
+                "tags": {
+                    "description": "tags to send to a group of contacts at once, stored in lowercase",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "team",
+                        "jakarta"
+                    ]
+                },
+                "timezone": {
+                    "description": "IANA timezone of the contact",
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                }
+            }
+        },
+        "models.FeedSendWhatsappReq": {
+            "type": "object",
+            "properties": {
+                "async": {
+                    "description": "if set to true, return 202 with a job id right away and run the broadcast in the background",
+                    "type": "boolean",
+                    "example": false
+                },
+                "contact_ids": {
+                    "description": "also send to the contacts with the ids",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "feed_url": {
+                    "description": "required, url of an RSS 2.0 or Atom feed",
+                    "type": "string",
+                    "example": "https://www.theverge.com/rss/index.xml"
+                },
+                "limit": {
+                    "description": "latest items to send, 1 to 10, default 10",
+                    "type": "integer",
+                    "example": 10
+                },
+                "rich": {
+                    "description": "if set to true, every item with a picture is sent as an image message and the llm summary follows as a text message",
+                    "type": "boolean",
+                    "example": false
+                },
+                "session": {
+                    "description": "whatsapp session to send from, the default session when empty",
+                    "type": "string",
+                    "example": "default"
+                },
+                "skip_invalid": {
+                    "description": "if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request",
+                    "type": "boolean",
+                    "example": false
+                },
+                "tags": {
+                    "description": "also send to the contacts with at least one of the tags",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "team"
+                    ]
+                },
+                "typing": {
+                    "description": "if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural",
+                    "type": "boolean",
+                    "example": false
+                },
+                "using_llm": {
+                    "description": "options: true, false, if set to true, the llm summary of the items is added at the end",
+                    "type": "boolean",
+                    "example": true
+                },
+                "whatsapp_numbers": {
+                    "description": "list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6285727771234",
+                        "6285667889887"
+                    ]
+                }
+            }
+        },
+        "models.NewsSearchSendWhatsappReq": {
+            "type": "object",
+            "properties": {
+                "async": {
+                    "description": "if set to true, return 202 with a job id right away and run the broadcast in the background",
+                    "type": "boolean",
+                    "example": false
+                },
+                "contact_ids": {
+                    "description": "also send to the contacts with the ids",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "domains": {
+                    "description": "comma separated domains to restrict the search to",
+                    "type": "string",
+                    "example": "bbc.co.uk,techcrunch.com"
+                },
+                "exclude_domains": {
+                    "description": "comma separated domains to leave out",
+                    "type": "string",
+                    "example": "example.com"
+                },
+                "from": {
+                    "description": "oldest article, YYYY-MM-DD or YYYY-MM-DDTHH:MM:SSZ",
+                    "type": "string",
+                    "example": "2025-04-01"
+                },
+                "language": {
+                    "description": "options: ar, de, en, es, fr, he, it, nl, no, pt, ru, sv, ud, zh, default all languages",
+                    "type": "string",
+                    "example": "en"
+                },
+                "limit": {
+                    "description": "articles to send, 1 to 10, default 10",
+                    "type": "integer",
+                    "example": 10
+                },
+                "query": {
+                    "description": "keywords or phrase to search for, supports \"exact match\", AND, OR and NOT, required when domains and sources are empty",
+                    "type": "string",
+                    "example": "\"Acme Corp\" OR Globex"
+                },
+                "rich": {
+                    "description": "if set to true, every article is sent as an image message with the article picture and the llm summary follows as a text message",
+                    "type": "boolean",
+                    "example": false
+                },
+                "search_in": {
+                    "description": "comma separated fields to search the query in, options: title, description, content, default all of them",
+                    "type": "string",
+                    "example": "title,description"
+                },
+                "session": {
+                    "description": "whatsapp session to send from, the default session when empty",
+                    "type": "string",
+                    "example": "default"
+                },
+                "skip_invalid": {
+                    "description": "if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request",
+                    "type": "boolean",
+                    "example": false
+                },
+                "sort_by": {
+                    "description": "options: relevancy, popularity, publishedAt, default publishedAt",
+                    "type": "string",
+                    "example": "publishedAt"
+                },
+                "sources": {
+                    "description": "comma separated source ids from /news/sources to restrict the search to, 20 at most",
+                    "type": "string",
+                    "example": "bbc-news,cnn"
+                },
+                "tags": {
+                    "description": "also send to the contacts with at least one of the tags",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "team"
+                    ]
+                },
+                "to": {
+                    "description": "newest article, YYYY-MM-DD or YYYY-MM-DDTHH:MM:SSZ",
+                    "type": "string",
+                    "example": "2025-04-07"
+                },
+                "typing": {
+                    "description": "if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural",
+                    "type": "boolean",
+                    "example": false
+                },
+                "using_llm": {
+                    "description": "options: true, false, if set to true, the llm summary of the found articles is added at the end",
+                    "type": "boolean",
+                    "example": true
+                },
+                "whatsapp_numbers": {
+                    "description": "list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6285727771234",
+                        "6285667889887"
+                    ]
+                }
+            }
+        },
+        "models.NewsSendWhatsappReq": {
+            "type": "object",
+            "properties": {
+                "async": {
+                    "description": "if set to true, return 202 with a job id right away and run the broadcast in the background",
+                    "type": "boolean",
+                    "example": false
+                },
+                "category": {
+                    "description": "options: business, entertainment, general, health, science, sports, technology. Category, country or sources is required",
+                    "type": "string",
+                    "example": "business"
+                },
+                "contact_ids": {
+                    "description": "also send to the contacts with the ids",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "country": {
+                    "description": "ISO 3166-1 code of the country of the headlines, like us or id",
+                    "type": "string",
+                    "example": "id"
+                },
+                "rich": {
+                    "description": "if set to true, every headline is sent as an image message with the article picture and the llm summary follows as a text message",
+                    "type": "boolean",
+                    "example": false
+                },
+                "session": {
+                    "description": "whatsapp session to send from, the default session when empty",
+                    "type": "string",
+                    "example": "default"
+                },
+                "skip_invalid": {
+                    "description": "if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request",
+                    "type": "boolean",
+                    "example": false
+                },
+                "sources": {
+                    "description": "comma separated source ids from /news/sources, 20 at most, can not be mixed with category or country",
+                    "type": "string",
+                    "example": "bbc-news,cnn"
+                },
+                "subscribers": {
+                    "description": "if set to true, also send to the numbers subscribed to the category through the bot, whatsapp_numbers, tags and contact_ids can then be empty",
+                    "type": "boolean",
+                    "example": false
+                },
+                "tags": {
+                    "description": "also send to the contacts with at least one of the tags",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "team"
+                    ]
+                },
+                "typing": {
+                    "description": "if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural",
+                    "type": "boolean",
+                    "example": false
+                },
+                "using_llm": {
+                    "description": "options: true, false, if set to true, the message news will be add with llm and if false, the message news will be add with the default message",
+                    "type": "boolean",
+                    "example": true
+                },
+                "whatsapp_numbers": {
+                    "description": "list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6285727771234",
+                        "6285667889887"
+                    ]
+                }
+            }
+        },
+        "models.ScheduleReq": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "news, options: business, entertainment, general, health, science, sports, technology. news needs a category, country or sources",
+                    "type": "string",
+                    "example": "technology"
+                },
+                "country": {
+                    "description": "news, ISO 3166-1 code of the country of the headlines, like us or id",
+                    "type": "string",
+                    "example": "us"
+                },
+                "cron": {
+                    "description": "required, standard 5 fields cron expression or descriptor like @daily",
+                    "type": "string",
+                    "example": "0 7 * * *"
+                },
+                "enabled": {
+                    "description": "default true, disabled schedules are kept but never fired",
+                    "type": "boolean",
+                    "example": true
+                },
+                "feed_url": {
+                    "description": "required for feed, url of an RSS 2.0 or Atom feed",
+                    "type": "string",
+                    "example": "https://www.theverge.com/rss/index.xml"
+                },
+                "job_type": {
+                    "description": "required, options: news, weather, message, feed",
+                    "type": "string",
+                    "example": "news"
+                },
+                "lat": {
+                    "description": "required for weather, latitude of the location",
+                    "type": "number",
+                    "example": -6.2617
+                },
+                "lon": {
+                    "description": "required for weather, longitude of the location",
+                    "type": "number",
+                    "example": 106.8103
+                },
+                "messages": {
+                    "description": "required for message, message to be sent",
+                    "type": "string",
+                    "example": "Hello, this is a scheduled message"
+                },
+                "name": {
+                    "description": "required, name to recognize the schedule",
+                    "type": "string",
+                    "example": "Morning tech news"
+                },
+                "session": {
+                    "description": "whatsapp session to send from, default session when empty",
+                    "type": "string",
+                    "example": "default"
+                },
+                "sources": {
+                    "description": "news, comma separated NewsAPI source ids from /news/sources, 20 at most, can not be mixed with country or category",
+                    "type": "string",
+                    "example": "bbc-news,the-verge"
+                },
+                "subscribers": {
+                    "description": "news and weather only, also send to the numbers subscribed through the bot, weather subscribers get the weather of their shared location",
+                    "type": "boolean",
+                    "example": false
+                },
+                "timezone": {
+                    "description": "IANA timezone the cron expression is evaluated in, default UTC",
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                },
+                "typing": {
+                    "description": "options: true, false, show typing in the chat for a time based on the message length before every message",
+                    "type": "boolean",
+                    "example": false
+                },
+                "using_llm": {
+                    "description": "options: true, false, summarize news or weather with llm",
+                    "type": "boolean",
+                    "example": true
+                },
+                "weather_type": {
+                    "description": "required for weather, options: today, tomorrow",
+                    "type": "string",
+                    "example": "today"
+                },
+                "whatsapp_numbers": {
+                    "description": "list of numbers to send to and start with code number like 62 and not 0 like 08123456789, or group JIDs like 120363025246125486@g.us",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6285727771234",
+                        "6285667889887"
+                    ]
+                }
+            }
+        },
+        "models.WatchlistReq": {
+            "type": "object",
+            "properties": {
+                "domains": {
+                    "description": "comma separated domains to restrict the search to",
+                    "type": "string",
+                    "example": "techcrunch.com,bbc.co.uk"
+                },
+                "enabled": {
+                    "description": "default true, disabled watchlists are kept but never polled",
+                    "type": "boolean",
+                    "example": true
+                },
+                "exclude_domains": {
+                    "description": "comma separated domains to remove from the results",
+                    "type": "string",
+                    "example": "example.com"
+                },
+                "interval_minutes": {
+                    "description": "minutes between polls, 5 to 1440, default 30. Every poll is one NewsAPI request",
+                    "type": "integer",
+                    "example": 30
+                },
+                "language": {
+                    "description": "options: ar, de, en, es, fr, he, it, nl, no, pt, ru, sv, ud, zh, all languages when empty",
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "description": "required, name to recognize the watchlist, shown in the alert",
+                    "type": "string",
+                    "example": "Acme brand mentions"
+                },
+                "query": {
+                    "description": "required when domains and sources are empty, keywords or phrases, supports \"exact match\", AND, OR and NOT",
+                    "type": "string",
+                    "example": "\"Acme Corp\" OR Globex"
+                },
+                "search_in": {
+                    "description": "options: title, description, content, comma separated, all fields when empty",
+                    "type": "string",
+                    "example": "title,description"
+                },
+                "session": {
+                    "description": "whatsapp session to send from, default session when empty",
+                    "type": "string",
+                    "example": "default"
+                },
+                "sources": {
+                    "description": "comma separated source ids from /news/sources to restrict the search to, 20 at most",
+                    "type": "string",
+                    "example": "bbc-news,cnn"
+                },
+                "tags": {
+                    "description": "contacts with at least one of the tags are alerted too, looked up on every poll",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "pr-team"
+                    ]
+                },
+                "typing": {
+                    "description": "options: true, false, show typing in the chat before the alert",
+                    "type": "boolean",
+                    "example": false
+                },
+                "whatsapp_numbers": {
+                    "description": "list of numbers to alert, start with code number like 62 and not 0 like 08123456789, or group JIDs like 120363025246125486@g.us",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6285727771234",
+                        "6285667889887"
+                    ]
+                }
+            }
+        },
+        "models.WeatherSendWhatsappReq": {
+            "type": "object",
+            "properties": {
+                "async": {
+                    "description": "if set to true, return 202 with a job id right away and run the broadcast in the background",
+                    "type": "boolean",
+                    "example": false
+                },
+                "contact_ids": {
+                    "description": "also send to the contacts with the ids, contacts with a default location get the weather there",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "lat": {
+                    "description": "required, latitude of the location",
+                    "type": "number",
+                    "example": -6.2617
+                },
+                "lon": {
+                    "description": "required, longitude of the location",
+                    "type": "number",
+                    "example": 106.8103
+                },
+                "session": {
+                    "description": "whatsapp session to send from, the default session when empty",
+                    "type": "string",
+                    "example": "default"
+                },
+                "skip_invalid": {
+                    "description": "if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request",
+                    "type": "boolean",
+                    "example": false
+                },
+                "subscribers": {
+                    "description": "if set to true, also send to the weather subscribers of the bot with the weather of their shared location, whatsapp_numbers, tags and contact_ids can then be empty",
+                    "type": "boolean",
+                    "example": false
+                },
+                "tags": {
+                    "description": "also send to the contacts with at least one of the tags, contacts with a default location get the weather there",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "team"
+                    ]
+                },
+                "type": {
+                    "description": "required, options: today, tomorrow",
+                    "type": "string",
+                    "example": "today"
+                },
+                "typing": {
+                    "description": "if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural",
+                    "type": "boolean",
+                    "example": false
+                },
+                "using_llm": {
+                    "description": "options: true, false, if set to true, the message news will be add with llm and if false, the message news will be add with the default message",
+                    "type": "boolean",
+                    "example": true
+                },
+                "whatsapp_numbers": {
+                    "description": "list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6285727771234",
+                        "6285667889887"
+                    ]
+                }
+            }
+        },
+        "models.WhatsappCheckNumbersReq": {
+            "type": "object",
+            "properties": {
+                "session": {
+                    "description": "whatsapp session used to check, the default session when empty",
+                    "type": "string",
+                    "example": "default"
+                },
+                "whatsapp_numbers": {
+                    "description": "required, max 100 numbers or group JIDs to check",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "085727771234",
+                        "+62 856-6788-9887"
+                    ]
+                }
+            }
+        },
+        "models.WhatsappGroup": {
+            "type": "object",
+            "properties": {
+                "is_announce": {
+                    "description": "only admins can send messages",
+                    "type": "boolean",
+                    "example": false
+                },
+                "jid": {
+                    "description": "use it in whatsapp_numbers to send to the group",
+                    "type": "string",
+                    "example": "120363025246125486@g.us"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Team Alerts"
+                },
+                "participants": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "Daily alerts for the team"
+                }
+            }
+        },
+        "models.WhatsappJoinGroupReq": {
+            "type": "object",
+            "properties": {
+                "invite_link": {
+                    "description": "required, invite link or only its code",
+                    "type": "string",
+                    "example": "https://chat.whatsapp.com/AbCdEfGhIjKlMnOpQrStUv"
+                },
+                "session": {
+                    "description": "whatsapp session that joins the group, the default session when empty",
+                    "type": "string",
+                    "example": "default"
+                }
+            }
+        },
+        "models.WhatsappMessagesReq": {
+            "type": "object",
+            "properties": {
+                "async": {
+                    "description": "if set to true, return 202 with a job id right away and send in the background",
+                    "type": "boolean",
+                    "example": false
+                },
+                "contact_ids": {
+                    "description": "also send to the contacts with the ids",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                },
+                "messages": {
+                    "description": "message to be sent to the whatsapp numbers",
+                    "type": "string",
+                    "example": "Hello, this is a test message"
+                },
+                "session": {
+                    "description": "whatsapp session to send from, the default session when empty",
+                    "type": "string",
+                    "example": "default"
+                },
+                "skip_invalid": {
+                    "description": "if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request",
+                    "type": "boolean",
+                    "example": false
+                },
+                "tags": {
+                    "description": "also send to the contacts with at least one of the tags",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "team"
+                    ]
+                },
+                "typing": {
+                    "description": "if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural",
+                    "type": "boolean",
+                    "example": false
+                },
+                "whatsapp_numbers": {
+                    "description": "list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6285727771234",
+                        "6285667889887"
+                    ]
+                }
+            }
+        },
+        "models.WhatsappNumberCheck": {
+            "type": "object",
+            "properties": {
+                "input": {
+                    "description": "number as given in the request",
+                    "type": "string",
+                    "example": "085727771234"
+                },
+                "is_group": {
+                    "description": "group JIDs are not checked with WhatsApp",
+                    "type": "boolean",
+                    "example": false
+                },
+                "number": {
+                    "description": "normalized number, use it in whatsapp_numbers",
+                    "type": "string",
+                    "example": "6285727771234"
+                },
+                "on_whatsapp": {
+                    "description": "the number is registered on WhatsApp",
+                    "type": "boolean",
+                    "example": true
+                },
+                "reason": {
+                    "description": "why the number is not valid",
+                    "type": "string"
+                },
+                "valid": {
+                    "description": "the number can be sent to",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.WhatsappPairCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "enter it on the phone from WhatsApp \u003e Linked devices \u003e Link with phone number instead",
+                    "type": "string",
+                    "example": "ABCD-EFGH"
+                },
+                "session": {
+                    "type": "string",
+                    "example": "default"
+                }
+            }
+        },
+        "models.WhatsappPairPhoneReq": {
+            "type": "object",
+            "properties": {
+                "phone_number": {
+                    "description": "required, number of the phone that links the session, a leading 0 gets the DEFAULT_COUNTRY_CODE",
+                    "type": "string",
+                    "example": "6285727771234"
+                },
+                "session": {
+                    "description": "whatsapp session to pair, the default session when empty",
+                    "type": "string",
+                    "example": "default"
+                }
+            }
+        },
+        "models.WhatsappSendResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "reason the message failed to send",
+                    "type": "string"
+                },
+                "message_id": {
+                    "description": "WhatsApp message ID, only set when the message is sent",
+                    "type": "string",
+                    "example": "3EB0C431C26A1916E4A2"
+                },
+                "number": {
+                    "description": "recipient number the message was sent to",
+                    "type": "string",
+                    "example": "6285727771234"
+                },
+                "outbox_id": {
+                    "description": "id of the message in the outbox, use it to inspect or replay the message",
+                    "type": "integer",
+                    "example": 1
+                },
+                "retry_at": {
+                    "description": "when a deferred message is sent",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "options: sent, queued, deferred, retrying, failed, invalid",
+                    "type": "string",
+                    "example": "sent"
+                },
+                "timestamp": {
+                    "description": "server timestamp of the sent message",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WhatsappSession": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "is_connected": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "is_logged_in": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "jid": {
+                    "description": "device of the session, empty until it is paired",
+                    "type": "string",
+                    "example": "6285727771234:12@s.whatsapp.net"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "alerts"
+                },
+                "qr_ready": {
+                    "description": "a QR code is waiting to be scanned, get it from the pair endpoint",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "models.WhatsappSessionPair": {
+            "type": "object",
+            "properties": {
+                "is_logged_in": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "alerts"
+                },
+                "qr_code": {
+                    "description": "scan it from WhatsApp \u003e Linked devices, it rotates every few seconds",
+                    "type": "string"
+                },
+                "qr_ready": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.WhatsappSessionReq": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "required, 1 to 32 letters, numbers, - or _",
+                    "type": "string",
+                    "example": "alerts"
+                }
+            }
+        },
+        "newsapi.Source": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "general"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "gb"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Use BBC News for up-to-the-minute news, breaking news, video, audio and feature stories."
+                },
+                "id": {
+                    "description": "identifier to use in the sources filter of the other requests",
+                    "type": "string",
+                    "example": "bbc-news"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "BBC News"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://www.bbc.co.uk/news"
+                }
+            }
+        },
+        "outbox.Message": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "body": {
+                    "type": "string",
+                    "example": "Hello, this is a test message"
+                },
+                "broadcast": {
+                    "description": "options: news, weather, custom, media, reply",
+                    "type": "string",
+                    "example": "weather"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deferred_until": {
+                    "description": "set while the rate limiter holds the message back, cleared on the next attempt",
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "media_id": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "played_at": {
+                    "description": "audio and video only",
+                    "type": "string"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "receipt": {
+                    "description": "options: delivered, read, played, empty until the recipient sends the first receipt",
+                    "type": "string",
+                    "example": "read"
+                },
+                "recipient": {
+                    "type": "string",
+                    "example": "6285727771234"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "session": {
+                    "description": "whatsapp session the message is sent from",
+                    "type": "string",
+                    "example": "default"
+                },
+                "source": {
+                    "description": "options: api, schedule, bot, watchlist",
+                    "type": "string",
+                    "example": "api"
+                },
+                "source_ref": {
+                    "description": "request id of the api call, schedule id, bot command or watchlist id",
+                    "type": "string",
+                    "example": "b2c4f3a0-1c7e-4b6e-9a55-5f0e2c1d8a11"
+                },
+                "state": {
+                    "description": "status moved on by the receipts, options: pending, sending, sent, delivered, read, played, dead",
+                    "type": "string",
+                    "example": "read"
+                },
+                "status": {
+                    "description": "options: pending, sending, sent, dead",
+                    "type": "string",
+                    "example": "sent"
+                },
+                "type": {
+                    "description": "options: text, image, document, audio, video",
+                    "type": "string",
+                    "example": "text"
+                },
+                "typing": {
+                    "description": "typing is shown in the chat before the message is sent",
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "using_llm": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "wa_message_id": {
+                    "type": "string",
+                    "example": "3EB0C431C26A1916E4A2"
+                }
+            }
+        },
+        "scheduler.Run": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WhatsappSendResult"
+                    }
+                },
+                "schedule_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "sent": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "options: running, sending, success, partial, failed, skipped",
+                    "type": "string",
+                    "example": "success"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "scheduler.Schedule": {
             "type": "object",
             "properties": {
+                "category": {
+                    "description": "news only",
+                    "type": "string",
+                    "example": "technology"
+                },
+                "country": {
+                    "description": "news only",
+                    "type": "string",
+                    "example": "us"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cron": {
+                    "type": "string",
+                    "example": "0 7 * * *"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "feed_url": {
+                    "description": "feed only, RSS 2.0 or Atom",
+                    "type": "string",
+                    "example": "https://www.theverge.com/rss/index.xml"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "job_type": {
+                    "description": "options: news, weather, message, feed",
+                    "type": "string",
+                    "example": "news"
+                },
                 "lat": {
-                    "description": "required, latitude of the location",
+                    "description": "weather only",
                     "type": "number",
                     "example": -6.2617
                 },
                 "lon": {
-                    "description": "required, longitude of the location",
+                    "description": "weather only",
                     "type": "number",
                     "example": 106.8103
                 },
-                "type": {
-                    "description": "required, options: today, tomorrow",
+                "messages": {
+                    "description": "message only",
                     "type": "string",
-                    "example": "today"
+                    "example": "Hello there"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Morning tech news"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "session": {
+                    "description": "whatsapp session the schedule sends from",
+                    "type": "string",
+                    "example": "default"
+                },
+                "sources": {
+                    "description": "news only, can not be mixed with country or category",
+                    "type": "string",
+                    "example": "bbc-news,the-verge"
+                },
+                "subscribers": {
+                    "description": "news and weather only, also send to the subscribers of the session",
+                    "type": "boolean",
+                    "example": false
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Asia/Jakarta"
+                },
+                "typing": {
+                    "description": "show typing in the chat before every message",
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated_at": {
+                    "type": "string"
                 },
                 "using_llm": {
-                    "description": "options: true, false, if set to true, the message news will be add with llm and if false, the message news will be add with the default message",
                     "type": "boolean",
                     "example": true
                 },
+                "weather_type": {
+                    "description": "weather only, options: today, tomorrow",
+                    "type": "string",
+                    "example": "today"
+                },
                 "whatsapp_numbers": {
-                    "description": "list of numbers to send the news to and start with code number like 62 and not 0 like 08123456789",
                     "type": "array",
                     "items": {
                         "type": "string"
@@ -321,24 +5451,33 @@ const docTemplate = `{
                 }
             }
         },
-        "models.WhatsappMessagesReq": {
+        "subscriptions.Subscription": {
             "type": "object",
             "properties": {
-                "messages": {
-                    "description": "message to be sent to the whatsapp numbers",
+                "category": {
+                    "description": "news only",
                     "type": "string",
-                    "example": "Hello, this is a test message"
+                    "example": "technology"
                 },
-                "whatsapp_numbers": {
-                    "description": "list of numbers to send the news to and start with code number like 62 and not 0 like 08123456789",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "6285727771234",
-                        "6285667889887"
-                    ]
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "number": {
+                    "type": "string",
+                    "example": "6285727771234"
+                },
+                "session": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "topic": {
+                    "description": "options: news, weather",
+                    "type": "string",
+                    "example": "news"
                 }
             }
         },
@@ -367,11 +5506,175 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "watchlists.Article": {
+            "type": "object",
+            "properties": {
+                "alerted": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "seen_at": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "TechCrunch"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Acme raises its series B"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://techcrunch.com/2025/04/07/acme-raises"
+                }
+            }
+        },
+        "watchlists.PollResult": {
+            "type": "object",
+            "properties": {
+                "baseline": {
+                    "description": "first poll of the search, the articles are only remembered",
+                    "type": "boolean",
+                    "example": false
+                },
+                "found": {
+                    "description": "articles returned by the search",
+                    "type": "integer",
+                    "example": 10
+                },
+                "new": {
+                    "description": "articles the watchlist had not seen before",
+                    "type": "integer",
+                    "example": 2
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WhatsappSendResult"
+                    }
+                }
+            }
+        },
+        "watchlists.Watchlist": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "domains": {
+                    "description": "comma separated domains to restrict the search to",
+                    "type": "string",
+                    "example": "techcrunch.com"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "exclude_domains": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "interval_minutes": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "last_error": {
+                    "description": "error of the last poll, empty when it went fine",
+                    "type": "string"
+                },
+                "last_polled_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme brand mentions"
+                },
+                "next_poll_at": {
+                    "type": "string"
+                },
+                "query": {
+                    "description": "NewsAPI everything q, supports \"exact match\", AND, OR and NOT",
+                    "type": "string",
+                    "example": "\"Acme Corp\" OR Globex"
+                },
+                "search_in": {
+                    "description": "options: title, description, content, comma separated",
+                    "type": "string",
+                    "example": "title"
+                },
+                "session": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "sources": {
+                    "description": "comma separated source ids to restrict the search to",
+                    "type": "string",
+                    "example": "bbc-news"
+                },
+                "tags": {
+                    "description": "contacts with one of the tags also get the alerts, resolved on every poll",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "pr-team"
+                    ]
+                },
+                "typing": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "whatsapp_numbers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "6285727771234"
+                    ]
+                }
+            }
+        },
+        "whatsapp.Event": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "MessageEvent, ReceiptEvent, LoggedOutEvent, QREvent or PairedEvent, empty for the connection events"
+                },
+                "session": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
-        "BasicAuth": {
-            "type": "basic"
+        "ApiKeyAuth": {
+            "description": "API key with the scope of the route: send, status, logout or admin. \"Authorization: Bearer \u003ckey\u003e\" works too",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     },
     "externalDocs": {
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// HeaderAPIKey carries the api key, "Authorization: Bearer <key>" works too
+const HeaderAPIKey = "X-API-Key"
+
+// key of the fiber locals that tells the handlers the caller is an admin
+const localsAdmin = "auth_admin"
+
+// Auth checks the api key of every request against the scope of the route and keeps an audit log of the calls.
+// Requests without a key are let in when they carry the session of the web dashboard login
+type Auth struct {
+	store *Store
+	web   *WebLogin
+}
+
+func New(store *Store, web *WebLogin) *Auth {
+	return &Auth{
+		store: store,
+		web:   web,
+	}
+}
+
+func (a *Auth) Store() *Store {
+	return a.store
+}
+
+// DefaultKeyFile is where Bootstrap writes the admin key it issues when no file is given
+const DefaultKeyFile = "admin_api_key.txt"
+
+// Bootstrap makes sure the api can be used after the first start. The key in raw is stored as an admin key when set
+// and not stored yet, a revoked one stays revoked. Otherwise a random admin key is issued when there is no active key at all. The issued key is written once
+// to key_file, readable by the owner only, and only its prefix is logged so it never ends up in the logs
+func (a *Auth) Bootstrap(ctx context.Context, raw, key_file string) error {
+	if raw != "" {
+		key, err := a.store.Ensure(ctx, "bootstrap", raw, []string{ScopeAdmin})
+		if err != nil {
+			return err
+		}
+
+		if key.RevokedAt != nil {
+			log.Printf("ADMIN_API_KEY %s... is revoked, it is not restored on start, set a new key to use one\n", key.Prefix)
+		}
+		return nil
+	}
+
+	n, err := a.store.CountActive(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+
+	if key_file == "" {
+		key_file = DefaultKeyFile
+	}
+
+	// an existing file is never overwritten, it may hold the key of another install
+	file, err := os.OpenFile(key_file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("no api key found and the admin key can not be written to %s, set ADMIN_API_KEY instead: %w", key_file, err)
+	}
+	defer file.Close()
+
+	key, raw, err := a.store.Create(ctx, "bootstrap", []string{ScopeAdmin})
+	if err != nil {
+		os.Remove(key_file)
+		return err
+	}
+
+	if _, err := file.WriteString(raw + "\n"); err != nil {
+		return fmt.Errorf("failed to write the admin key %s to %s: %w", key.Prefix, key_file, err)
+	}
+
+	log.Printf("No api key found, issued admin key %s... and wrote it to %s, store it somewhere safe and delete the file\n", key.Prefix, key_file)
+	return nil
+}
+
+// keyFromRequest reads the key from the X-API-Key header or the bearer token
+func keyFromRequest(c *fiber.Ctx) string {
+	if raw := c.Get(HeaderAPIKey); raw != "" {
+		return raw
+	}
+
+	if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+
+	return ""
+}
+
+// Require answers 401 without a valid key and 403 when the key misses the scope,
+// every authenticated call is written to the audit log after the handler ran
+func (a *Auth) Require(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		raw := keyFromRequest(c)
+		if raw == "" {
+			return a.requireWeb(c)
+		}
+
+		key, err := a.store.Authenticate(c.UserContext(), raw)
+		if errors.Is(err, ErrInvalidKey) {
+			return utils.ResponseError(c, fiber.StatusUnauthorized, "Invalid or revoked API key")
+		}
+		if err != nil {
+			return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to check API key: "+err.Error())
+		}
+
+		if !key.Allows(scope) {
+			return utils.ResponseError(c, fiber.StatusForbidden, "API key does not have the "+scope+" scope")
+		}
+
+		c.Locals(localsAdmin, key.Allows(ScopeAdmin))
+
+		handler_err := c.Next()
+		a.audit(c, key.ID, key.Name, handler_err)
+
+		return handler_err
+	}
+}
+
+// requireWeb lets in the admin of the web dashboard, the cookie is sent by the browser on its own
+// so every unsafe request also needs the csrf token of the session
+func (a *Auth) requireWeb(c *fiber.Ctx) error {
+	user, csrf, ok := "", "", false
+	if a.web != nil {
+		user, csrf, ok = a.web.Admin(c)
+	}
+	if !ok {
+		return utils.ResponseError(c, fiber.StatusUnauthorized, "API key is required, set the "+HeaderAPIKey+" header")
+	}
+
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead && !a.web.CheckCSRF(c, csrf) {
+		return utils.ResponseError(c, fiber.StatusForbidden, "Invalid CSRF token")
+	}
+
+	c.Locals(localsAdmin, true)
+
+	handler_err := c.Next()
+	a.audit(c, 0, "web:"+user, handler_err)
+
+	return handler_err
+}
+
+// IsAdmin reports if the request was authenticated with an admin key or the web dashboard login
+func IsAdmin(c *fiber.Ctx) bool {
+	admin, _ := c.Locals(localsAdmin).(bool)
+	return admin
+}
+
+// audit records the call in the background so the response is not delayed
+func (a *Auth) audit(c *fiber.Ctx, key_id int64, key_name string, handler_err error) {
+	status := c.Response().StatusCode()
+	var fiber_err *fiber.Error
+	if errors.As(handler_err, &fiber_err) {
+		status = fiber_err.Code
+	} else if handler_err != nil {
+		status = fiber.StatusInternalServerError
+	}
+
+	// copy the values, the fiber context is reused once the handler returns
+	entry := AuditEntry{
+		KeyID:     key_id,
+		KeyName:   key_name,
+		Method:    c.Method(),
+		Path:      strings.Clone(c.Path()),
+		Status:    status,
+		IP:        c.IP(),
+		RequestID: strings.Clone(c.GetRespHeader(fiber.HeaderXRequestID)),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := a.store.Audit(ctx, entry); err != nil {
+			log.Println("Failed to write audit log: " + err.Error())
+		}
+	}()
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// scopes of an api key, admin is allowed everywhere
+const (
+	ScopeSend   = "send"   // send messages, news, weather and media
+	ScopeStatus = "status" // read the status, history and listings
+	ScopeLogout = "logout" // log a whatsapp session out
+	ScopeAdmin  = "admin"  // manage sessions, schedules, contacts and api keys
+)
+
+var Scopes = []string{ScopeSend, ScopeStatus, ScopeLogout, ScopeAdmin}
+
+// APIKey is a key allowed to call the api, only the sha256 of the key is stored
+type APIKey struct {
+	ID         int64      `json:"id" example:"1"`
+	Name       string     `json:"name" example:"reporting"`
+	Prefix     string     `json:"prefix" example:"wan_3f9a1c"` // start of the key to recognize it
+	Scopes     []string   `json:"scopes" example:"send,status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Allows reports if the key has the scope, admin keys have every scope
+func (k *APIKey) Allows(scope string) bool {
+	return slices.Contains(k.Scopes, ScopeAdmin) || slices.Contains(k.Scopes, scope)
+}
+
+// AuditEntry is one api call made with a key
+type AuditEntry struct {
+	ID        int64     `json:"id" example:"1"`
+	KeyID     int64     `json:"key_id" example:"1"`           // 0 for the web dashboard login
+	KeyName   string    `json:"key_name" example:"reporting"` // name of the key or web:<username> for the web dashboard login
+	Method    string    `json:"method" example:"POST"`
+	Path      string    `json:"path" example:"/api/wa/news"`
+	Status    int       `json:"status" example:"200"`
+	IP        string    `json:"ip" example:"10.0.0.12"`
+	RequestID string    `json:"request_id" example:"b2c4f3a0-1c7e-4b6e-9a55-5f0e2c1d8a11"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidateScopes checks that every scope is known and that there is at least one
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("scopes is required, options: send, status, logout, admin")
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return fmt.Errorf("unknown scope %s, options: send, status, logout, admin", scope)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrNotFound   = errors.New("api key not found")
+	ErrInvalidKey = errors.New("invalid or revoked api key")
+)
+
+// every key starts with this so a leaked key is easy to recognize
+const keyPrefix = "wan_"
+
+const keyColumns = `id, name, prefix, scopes, created_at, last_used_at, revoked_at`
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Migrate creates the api key and audit log tables if they do not exist yet
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS wa_api_keys (
+			id           BIGSERIAL PRIMARY KEY,
+			name         TEXT        NOT NULL,
+			prefix       TEXT        NOT NULL,
+			key_hash     TEXT        NOT NULL UNIQUE,
+			scopes       TEXT[]      NOT NULL,
+			created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+			last_used_at TIMESTAMPTZ,
+			revoked_at   TIMESTAMPTZ
+		);
+
+		CREATE TABLE IF NOT EXISTS wa_audit_log (
+			id         BIGSERIAL PRIMARY KEY,
+			key_id     BIGINT      NOT NULL REFERENCES wa_api_keys (id),
+			key_name   TEXT        NOT NULL,
+			method     TEXT        NOT NULL,
+			path       TEXT        NOT NULL,
+			status     INT         NOT NULL,
+			ip         TEXT        NOT NULL DEFAULT '',
+			request_id TEXT        NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS wa_audit_log_key_idx ON wa_audit_log (key_id, id);
+		ALTER TABLE wa_audit_log ALTER COLUMN key_id DROP NOT NULL;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate api key tables: %w", err)
+	}
+
+	return nil
+}
+
+// hashKey is the sha256 of the key, keys are random so a slow hash is not needed
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func newKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+func displayPrefix(raw string) string {
+	if len(raw) > len(keyPrefix)+6 {
+		return raw[:len(keyPrefix)+6]
+	}
+
+	return raw
+}
+
+func scanKey(row interface{ Scan(dest ...any) error }) (APIKey, error) {
+	var key APIKey
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return APIKey{}, ErrNotFound
+	}
+
+	return key, err
+}
+
+// Create issues a new key, the raw key is returned only here and can not be read again
+func (s *Store) Create(ctx context.Context, name string, scopes []string) (APIKey, string, error) {
+	raw, err := newKey()
+	if err != nil {
+		return APIKey{}, "", err
+	}
+
+	key, err := scanKey(s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_api_keys (name, prefix, key_hash, scopes) VALUES ($1, $2, $3, $4)
+		RETURNING `+keyColumns,
+		name, displayPrefix(raw), hashKey(raw), pq.Array(scopes)))
+	return key, raw, err
+}
+
+// Ensure stores a key chosen by the operator, like the bootstrap admin key from the env, when it is not stored yet.
+// A stored key is returned as it is, so a key revoked through the api stays revoked
+func (s *Store) Ensure(ctx context.Context, name, raw string, scopes []string) (APIKey, error) {
+	key, err := scanKey(s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_api_keys (name, prefix, key_hash, scopes) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key_hash) DO NOTHING
+		RETURNING `+keyColumns,
+		name, displayPrefix(raw), hashKey(raw), pq.Array(scopes)))
+	if !errors.Is(err, ErrNotFound) {
+		return key, err
+	}
+
+	return scanKey(s.db.QueryRowContext(ctx, `SELECT `+keyColumns+` FROM wa_api_keys WHERE key_hash = $1`, hashKey(raw)))
+}
+
+// CountActive returns how many keys are not revoked
+func (s *Store) CountActive(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM wa_api_keys WHERE revoked_at IS NULL`).Scan(&n)
+	return n, err
+}
+
+// Authenticate returns the active key with the raw value and marks it as used
+func (s *Store) Authenticate(ctx context.Context, raw string) (APIKey, error) {
+	key, err := scanKey(s.db.QueryRowContext(ctx, `
+		UPDATE wa_api_keys SET last_used_at = now()
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING `+keyColumns, hashKey(raw)))
+	if errors.Is(err, ErrNotFound) {
+		return APIKey{}, ErrInvalidKey
+	}
+
+	return key, err
+}
+
+// List returns every key newest first, revoked keys included
+func (s *Store) List(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+keyColumns+` FROM wa_api_keys ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		key, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke disables the key, it stays in the list for the audit log
+func (s *Store) Revoke(ctx context.Context, id int64) (APIKey, error) {
+	return scanKey(s.db.QueryRowContext(ctx, `
+		UPDATE wa_api_keys SET revoked_at = COALESCE(revoked_at, now())
+		WHERE id = $1
+		RETURNING `+keyColumns, id))
+}
+
+func (s *Store) Audit(ctx context.Context, entry AuditEntry) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO wa_audit_log (key_id, key_name, method, path, status, ip, request_id)
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7)`,
+		entry.KeyID, entry.KeyName, entry.Method, entry.Path, entry.Status, entry.IP, entry.RequestID)
+	return err
+}
+
+// ListAudit returns the audit log newest first, filtered by key when key_id is not 0.
+// Calls of the web dashboard login have key_id 0
+func (s *Store) ListAudit(ctx context.Context, key_id int64, limit, offset int) ([]AuditEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, COALESCE(key_id, 0), key_name, method, path, status, ip, request_id, created_at FROM wa_audit_log
+		WHERE ($1 = 0 OR key_id = $1)
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3`, key_id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var entry AuditEntry
+		if err := rows.Scan(
+			&entry.ID, &entry.KeyID, &entry.KeyName, &entry.Method, &entry.Path, &entry.Status, &entry.IP, &entry.RequestID, &entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
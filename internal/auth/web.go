@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HeaderCSRF carries the csrf token of the web session on every request that changes something
+const HeaderCSRF = "X-CSRF-Token"
+
+// name of the session cookie and the keys of the session values
+const (
+	sessionCookie = "wa_notifier_session"
+	sessionUser   = "user"
+	sessionCSRF   = "csrf"
+)
+
+var (
+	ErrInvalidLogin = errors.New("invalid username or password")
+	ErrLoginOff     = errors.New("web login is not configured, set ADMIN_USERNAME and ADMIN_PASSWORD_HASH")
+)
+
+// WebLogin is the session cookie login of the web dashboard for the admin user configured in the env,
+// a logged in admin has every scope and needs the csrf token of the session for unsafe requests
+type WebLogin struct {
+	username     string
+	passwordHash []byte
+	sessions     *session.Store
+}
+
+// NewWebLogin checks the bcrypt hash, the login stays off when the username or the hash is empty
+func NewWebLogin(username, password_hash string, secure_cookie bool) (*WebLogin, error) {
+	if password_hash != "" {
+		if _, err := bcrypt.Cost([]byte(password_hash)); err != nil {
+			return nil, fmt.Errorf("ADMIN_PASSWORD_HASH is not a bcrypt hash: %w", err)
+		}
+	}
+
+	return &WebLogin{
+		username:     username,
+		passwordHash: []byte(password_hash),
+		sessions: session.New(session.Config{
+			Expiration:     12 * time.Hour,
+			KeyLookup:      "cookie:" + sessionCookie,
+			CookieHTTPOnly: true,
+			CookieSecure:   secure_cookie,
+			CookieSameSite: fiber.CookieSameSiteStrictMode,
+		}),
+	}, nil
+}
+
+func (w *WebLogin) Enabled() bool {
+	return w.username != "" && len(w.passwordHash) > 0
+}
+
+// Login checks the credentials and starts a new session with a fresh csrf token
+func (w *WebLogin) Login(c *fiber.Ctx, username, password string) error {
+	if !w.Enabled() {
+		return ErrLoginOff
+	}
+
+	// always run bcrypt so a wrong username takes as long as a wrong password
+	password_err := bcrypt.CompareHashAndPassword(w.passwordHash, []byte(password))
+	if subtle.ConstantTimeCompare([]byte(username), []byte(w.username)) != 1 || password_err != nil {
+		return ErrInvalidLogin
+	}
+
+	sess, err := w.sessions.Get(c)
+	if err != nil {
+		return err
+	}
+
+	// a new session id on login so a session id planted before the login is useless
+	if err := sess.Regenerate(); err != nil {
+		return err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	sess.Set(sessionUser, w.username)
+	sess.Set(sessionCSRF, token)
+
+	return sess.Save()
+}
+
+func (w *WebLogin) Logout(c *fiber.Ctx) error {
+	sess, err := w.sessions.Get(c)
+	if err != nil {
+		return err
+	}
+
+	return sess.Destroy()
+}
+
+// Admin returns the user and the csrf token of the session, ok is false when nobody is logged in
+func (w *WebLogin) Admin(c *fiber.Ctx) (user, csrf string, ok bool) {
+	if !w.Enabled() || c.Cookies(sessionCookie) == "" {
+		return "", "", false
+	}
+
+	sess, err := w.sessions.Get(c)
+	if err != nil {
+		return "", "", false
+	}
+
+	user, _ = sess.Get(sessionUser).(string)
+	csrf, _ = sess.Get(sessionCSRF).(string)
+
+	return user, csrf, user != "" && csrf != ""
+}
+
+// CheckCSRF compares the token of the X-CSRF-Token header or the csrf_token form field with the token of the session
+func (w *WebLogin) CheckCSRF(c *fiber.Ctx, csrf string) bool {
+	token := c.Get(HeaderCSRF)
+	if token == "" {
+		token = c.FormValue("csrf_token")
+	}
+
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(csrf)) == 1
+}
+
+// RequirePage sends visitors without a session to the login page
+func (w *WebLogin) RequirePage() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, _, ok := w.Admin(c); !ok {
+			return c.Redirect("/login")
+		}
+
+		return c.Next()
+	}
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
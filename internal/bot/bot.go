@@ -0,0 +1,314 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/internal/notifier"
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/internal/subscriptions"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// commands start with this prefix, every other message is ignored
+const commandPrefix = "!"
+
+// how long one command can take, weather with llm is the slowest
+const commandTimeout = 2 * time.Minute
+
+// DefaultCooldown is the minimum wait between two commands of the same number, repeats sooner are ignored
+const DefaultCooldown = 10 * time.Second
+
+var newsCategories = []string{"business", "entertainment", "general", "health", "science", "sports", "technology"}
+
+const helpMessage = `*Go WA Notifier commands*
+
+!subscribe news <category> - get the news of the category on every broadcast
+!subscribe weather - get the weather of your shared location on every broadcast
+!unsubscribe news <category> - stop the news of the category
+!unsubscribe weather - stop the weather
+!stop - stop everything
+!subscriptions - list what you get
+!weather today - weather of your shared location, also tomorrow
+!help - show this message
+
+News categories: business, entertainment, general, health, science, sports, technology
+Share your location from the attach menu to use the weather commands`
+
+// Bot answers the commands sent to the notifier numbers, so recipients can manage their own subscriptions
+// Every number can send one command per cooldown, so no one can flood the weather and llm apis or use up
+// the rate limit of the session with replies
+type Bot struct {
+	store    *subscriptions.Store
+	notifier *notifier.Notifier
+	usingLLM bool
+	cooldown time.Duration
+
+	mu   sync.Mutex
+	last map[string]time.Time // time of the last command by session and number
+}
+
+// New creates the bot, a zero cooldown uses DefaultCooldown and a negative one turns it off
+func New(store *subscriptions.Store, notifierService *notifier.Notifier, using_llm bool, cooldown time.Duration) *Bot {
+	if cooldown == 0 {
+		cooldown = DefaultCooldown
+	}
+
+	return &Bot{
+		store:    store,
+		notifier: notifierService,
+		usingLLM: using_llm,
+		cooldown: max(cooldown, 0),
+		last:     map[string]time.Time{},
+	}
+}
+
+// allow reports if the number is out of its cooldown and starts a new one when it is
+func (b *Bot) allow(session, number string) bool {
+	if b.cooldown == 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	key := session + "/" + number
+	if last, ok := b.last[key]; ok && now.Sub(last) < b.cooldown {
+		return false
+	}
+
+	// forget the numbers out of their cooldown once the map grows
+	if len(b.last) >= 1000 {
+		for k, last := range b.last {
+			if now.Sub(last) >= b.cooldown {
+				delete(b.last, k)
+			}
+		}
+	}
+	b.last[key] = now
+
+	return true
+}
+
+// Handle is registered as whatsapp.EventHandler, commands run in the background so the event loop is not blocked.
+// Only direct chats are handled, messages in groups and from the account itself are ignored
+func (b *Bot) Handle(evt whatsapp.Event) {
+	if evt.Type != whatsapp.EventMessage {
+		return
+	}
+
+	msg, ok := evt.Data.(whatsapp.MessageEvent)
+	if !ok || msg.FromMe || msg.IsGroup {
+		return
+	}
+
+	sender, err := types.ParseJID(msg.Sender)
+	if err != nil || sender.Server != types.DefaultUserServer {
+		return
+	}
+
+	text := strings.TrimSpace(msg.Text)
+	if msg.Kind != "location" && !strings.HasPrefix(text, commandPrefix) {
+		return
+	}
+
+	// repeats within the cooldown are dropped without a reply, a reply would use the send budget too
+	if !b.allow(evt.Session, sender.User) {
+		return
+	}
+
+	switch {
+	case msg.Kind == "location":
+		go b.run(evt.Session, sender.User, "location", func(ctx context.Context) (string, error) {
+			return b.saveLocation(ctx, sender.User, msg.Latitude, msg.Longitude)
+		})
+	case strings.HasPrefix(text, commandPrefix):
+		go b.run(evt.Session, sender.User, commandName(text), func(ctx context.Context) (string, error) {
+			return b.command(ctx, evt.Session, sender.User, text)
+		})
+	}
+}
+
+// commandName is the first word of the command, kept in the history of the reply
+func commandName(text string) string {
+	args := strings.Fields(strings.ToLower(strings.TrimPrefix(text, commandPrefix)))
+	if len(args) == 0 {
+		return "help"
+	}
+
+	return args[0]
+}
+
+// run executes the command and replies with its answer from the same session
+func (b *Bot) run(session, number, name string, command func(ctx context.Context) (string, error)) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	reply, err := command(ctx)
+	if err != nil {
+		log.Printf("Bot: command from %s failed: %s\n", number, err.Error())
+		reply = "Sorry, something went wrong, please try again later"
+	}
+
+	origin := outbox.Origin{
+		Broadcast: outbox.BroadcastReply,
+		Source:    outbox.SourceBot,
+		SourceRef: name,
+		UsingLLM:  b.usingLLM && name == "weather" && err == nil,
+	}
+
+	if _, err := b.notifier.Send(ctx, session, origin, reply, []string{number}); err != nil {
+		log.Printf("Bot: failed to reply to %s: %s\n", number, err.Error())
+	}
+}
+
+func (b *Bot) saveLocation(ctx context.Context, number string, lat, lon float64) (string, error) {
+	if err := b.store.SaveLocation(ctx, number, lat, lon); err != nil {
+		return "", err
+	}
+
+	return "Location saved. Send !weather today for the weather here or !subscribe weather to get it on every broadcast", nil
+}
+
+// command parses the text and runs the command, the returned text is the reply
+func (b *Bot) command(ctx context.Context, session, number, text string) (string, error) {
+	args := strings.Fields(strings.ToLower(strings.TrimPrefix(text, commandPrefix)))
+	if len(args) == 0 {
+		return helpMessage, nil
+	}
+
+	switch args[0] {
+	case "help", "start":
+		return helpMessage, nil
+	case "subscribe":
+		return b.subscribe(ctx, session, number, args[1:])
+	case "unsubscribe":
+		return b.unsubscribe(ctx, session, number, args[1:])
+	case "stop":
+		if _, err := b.store.Unsubscribe(ctx, session, number, "", ""); err != nil {
+			return "", err
+		}
+		return "You are unsubscribed from everything. Send !help to subscribe again", nil
+	case "subscriptions", "list":
+		return b.list(ctx, session, number)
+	case "weather":
+		return b.weather(ctx, number, args[1:])
+	default:
+		return fmt.Sprintf("Unknown command %s, send !help to see the commands", args[0]), nil
+	}
+}
+
+// topicArgs validates the topic and category of subscribe and unsubscribe
+func topicArgs(args []string, need_category bool) (string, string, string) {
+	if len(args) == 0 {
+		return "", "", "Tell me what to use, like !subscribe news technology or !subscribe weather"
+	}
+
+	switch args[0] {
+	case subscriptions.TopicNews:
+		if len(args) < 2 {
+			if need_category {
+				return "", "", "Pick a news category: " + strings.Join(newsCategories, ", ")
+			}
+			return subscriptions.TopicNews, "", ""
+		}
+		if !slices.Contains(newsCategories, args[1]) {
+			return "", "", fmt.Sprintf("Unknown category %s, pick one of: %s", args[1], strings.Join(newsCategories, ", "))
+		}
+		return subscriptions.TopicNews, args[1], ""
+	case subscriptions.TopicWeather:
+		return subscriptions.TopicWeather, "", ""
+	default:
+		return "", "", fmt.Sprintf("Unknown topic %s, use news or weather", args[0])
+	}
+}
+
+func (b *Bot) subscribe(ctx context.Context, session, number string, args []string) (string, error) {
+	topic, category, problem := topicArgs(args, true)
+	if problem != "" {
+		return problem, nil
+	}
+
+	if _, err := b.store.Subscribe(ctx, session, number, topic, category); err != nil {
+		return "", err
+	}
+
+	if topic == subscriptions.TopicNews {
+		return fmt.Sprintf("Subscribed to %s news. Send !unsubscribe news %s to stop", category, category), nil
+	}
+
+	if _, err := b.store.GetLocation(ctx, number); errors.Is(err, subscriptions.ErrNoLocation) {
+		return "Subscribed to the weather. Share your location from the attach menu so I know which weather to send", nil
+	}
+
+	return "Subscribed to the weather of your shared location. Send !unsubscribe weather to stop", nil
+}
+
+func (b *Bot) unsubscribe(ctx context.Context, session, number string, args []string) (string, error) {
+	topic, category, problem := topicArgs(args, false)
+	if problem != "" {
+		return problem, nil
+	}
+
+	removed, err := b.store.Unsubscribe(ctx, session, number, topic, category)
+	if err != nil {
+		return "", err
+	}
+
+	if removed == 0 {
+		return "You were not subscribed to that, send !subscriptions to see what you get", nil
+	}
+
+	return "Unsubscribed. Send !subscriptions to see what you still get", nil
+}
+
+func (b *Bot) list(ctx context.Context, session, number string) (string, error) {
+	subs, err := b.store.List(ctx, session, number, "")
+	if err != nil {
+		return "", err
+	}
+
+	if len(subs) == 0 {
+		return "You have no subscriptions, send !help to see what you can get", nil
+	}
+
+	lines := []string{"*Your subscriptions*"}
+	for _, sub := range subs {
+		if sub.Topic == subscriptions.TopicNews {
+			lines = append(lines, "- news "+sub.Category)
+		} else {
+			lines = append(lines, "- "+sub.Topic)
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (b *Bot) weather(ctx context.Context, number string, args []string) (string, error) {
+	report_type := "today"
+	if len(args) > 0 {
+		report_type = args[0]
+	}
+
+	if report_type != "today" && report_type != "tomorrow" {
+		return "Use !weather today or !weather tomorrow", nil
+	}
+
+	location, err := b.store.GetLocation(ctx, number)
+	if errors.Is(err, subscriptions.ErrNoLocation) {
+		return "Share your location from the attach menu first, then send !weather " + report_type + " again", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return b.notifier.BuildWeatherMessage(ctx, report_type, location.Lat, location.Lon, b.usingLLM, nil)
+}
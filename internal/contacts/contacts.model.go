@@ -0,0 +1,94 @@
+package contacts
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// Contact is a recipient kept in the directory, send endpoints can target contacts by id or tag
+type Contact struct {
+	ID        int64     `json:"id" example:"1"`
+	Name      string    `json:"name" example:"Budi Santoso"`
+	Number    string    `json:"number" example:"6285727771234"` // normalized number or group JID
+	Tags      []string  `json:"tags" example:"team,jakarta"`
+	Language  string    `json:"language,omitempty" example:"id"`           // preferred language, ISO 639-1 code
+	Timezone  string    `json:"timezone,omitempty" example:"Asia/Jakarta"` // IANA timezone of the contact
+	Lat       *float64  `json:"lat,omitempty" example:"-6.2617"`           // default location, used for the weather
+	Lon       *float64  `json:"lon,omitempty" example:"106.8103"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the contact and normalizes its number, tags and language
+func (c *Contact) Validate() error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if strings.TrimSpace(c.Number) == "" {
+		return fmt.Errorf("number is required")
+	}
+
+	check := whatsapp.NormalizeRecipients([]string{c.Number})[0]
+	if !check.Valid {
+		return fmt.Errorf("invalid number %s: %s", c.Number, check.Reason)
+	}
+	c.Number = check.Number
+
+	tags := []string{}
+	for _, tag := range c.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	c.Tags = tags
+
+	c.Language = strings.ToLower(strings.TrimSpace(c.Language))
+	if c.Language != "" && !languagePattern.MatchString(c.Language) {
+		return fmt.Errorf("invalid language %s, use an ISO 639-1 code like id or en", c.Language)
+	}
+
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %s, use an IANA name like Asia/Jakarta", c.Timezone)
+		}
+	}
+
+	if (c.Lat == nil) != (c.Lon == nil) {
+		return fmt.Errorf("lat and lon must be set together")
+	}
+
+	if c.Lat != nil && (*c.Lat < -90 || *c.Lat > 90) {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+
+	if c.Lon != nil && (*c.Lon < -180 || *c.Lon > 180) {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+
+	return nil
+}
+
+// HasLocation reports if the contact has a default location
+func (c *Contact) HasLocation() bool {
+	return c.Lat != nil && c.Lon != nil
+}
+
+// Numbers returns the numbers of the contacts
+func Numbers(contacts []Contact) []string {
+	numbers := make([]string, 0, len(contacts))
+	for _, contact := range contacts {
+		numbers = append(numbers, contact.Number)
+	}
+
+	return numbers
+}
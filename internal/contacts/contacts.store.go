@@ -0,0 +1,176 @@
+package contacts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrNotFound      = errors.New("contact not found")
+	ErrNumberExists  = errors.New("a contact with this number already exists")
+	uniqueViolation  = pq.ErrorCode("23505")
+	contactColumns   = `id, name, number, tags, language, timezone, lat, lon, created_at, updated_at`
+	contactOrderedBy = ` ORDER BY name, id`
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Migrate creates the contacts table if it does not exist yet
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS wa_contacts (
+			id         BIGSERIAL PRIMARY KEY,
+			name       TEXT             NOT NULL,
+			number     TEXT             NOT NULL UNIQUE,
+			tags       TEXT[]           NOT NULL DEFAULT '{}',
+			language   TEXT             NOT NULL DEFAULT '',
+			timezone   TEXT             NOT NULL DEFAULT '',
+			lat        DOUBLE PRECISION,
+			lon        DOUBLE PRECISION,
+			created_at TIMESTAMPTZ      NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ      NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS wa_contacts_tags_idx ON wa_contacts USING GIN (tags);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate contacts table: %w", err)
+	}
+
+	return nil
+}
+
+func scanContact(row interface{ Scan(dest ...any) error }) (Contact, error) {
+	var contact Contact
+	err := row.Scan(
+		&contact.ID, &contact.Name, &contact.Number, pq.Array(&contact.Tags), &contact.Language, &contact.Timezone,
+		&contact.Lat, &contact.Lon, &contact.CreatedAt, &contact.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Contact{}, ErrNotFound
+	}
+
+	var pq_err *pq.Error
+	if errors.As(err, &pq_err) && pq_err.Code == uniqueViolation {
+		return Contact{}, ErrNumberExists
+	}
+
+	if contact.Tags == nil {
+		contact.Tags = []string{}
+	}
+
+	return contact, err
+}
+
+func scanContacts(rows *sql.Rows) ([]Contact, error) {
+	defer rows.Close()
+
+	contacts := []Contact{}
+	for rows.Next() {
+		contact, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, rows.Err()
+}
+
+// List returns the contacts ordered by name, filtered by tag and by a search on the name or number when they are not empty
+func (s *Store) List(ctx context.Context, tag, search string, limit, offset int) ([]Contact, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+contactColumns+` FROM wa_contacts
+		WHERE ($1 = '' OR $1 = ANY(tags)) AND ($2 = '' OR name ILIKE '%' || $2 || '%' OR number LIKE '%' || $2 || '%')`+
+		contactOrderedBy+`
+		LIMIT $3 OFFSET $4`, tag, search, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanContacts(rows)
+}
+
+func (s *Store) Get(ctx context.Context, id int64) (Contact, error) {
+	return scanContact(s.db.QueryRowContext(ctx, `SELECT `+contactColumns+` FROM wa_contacts WHERE id = $1`, id))
+}
+
+func (s *Store) Create(ctx context.Context, contact Contact) (Contact, error) {
+	return scanContact(s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_contacts (name, number, tags, language, timezone, lat, lon)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+contactColumns,
+		contact.Name, contact.Number, pq.Array(contact.Tags), contact.Language, contact.Timezone, contact.Lat, contact.Lon,
+	))
+}
+
+func (s *Store) Update(ctx context.Context, contact Contact) (Contact, error) {
+	return scanContact(s.db.QueryRowContext(ctx, `
+		UPDATE wa_contacts
+		SET name = $2, number = $3, tags = $4, language = $5, timezone = $6, lat = $7, lon = $8, updated_at = now()
+		WHERE id = $1
+		RETURNING `+contactColumns,
+		contact.ID, contact.Name, contact.Number, pq.Array(contact.Tags), contact.Language, contact.Timezone, contact.Lat, contact.Lon,
+	))
+}
+
+func (s *Store) Delete(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM wa_contacts WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Resolve returns the contacts with one of the ids or with at least one of the tags.
+// Every id has to exist, an unknown id fails with ErrNotFound
+func (s *Store) Resolve(ctx context.Context, ids []int64, tags []string) ([]Contact, error) {
+	if len(ids) == 0 && len(tags) == 0 {
+		return []Contact{}, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+contactColumns+` FROM wa_contacts
+		WHERE id = ANY($1) OR tags && $2`+contactOrderedBy, pq.Array(ids), pq.Array(tags))
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := scanContacts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[int64]bool{}
+	for _, contact := range contacts {
+		found[contact.ID] = true
+	}
+
+	missing := []string{}
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, fmt.Sprint(id))
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ", "))
+	}
+
+	return contacts, nil
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/contacts"
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// for swagger docs
+type ContactResponse struct {
+	Error   bool             `json:"error" example:"false"`
+	Message string           `json:"message"`
+	Data    contacts.Contact `json:"data"`
+}
+
+// for swagger docs
+type ContactsResponse struct {
+	Error   bool               `json:"error" example:"false"`
+	Message string             `json:"message"`
+	Data    []contacts.Contact `json:"data"`
+}
+
+// contactFromReq fills the contact with the request body and validates it
+func contactFromReq(req_body *models.ContactReq, contact *contacts.Contact) error {
+	contact.Name = req_body.Name
+	contact.Number = req_body.Number
+	contact.Tags = req_body.Tags
+	contact.Language = req_body.Language
+	contact.Timezone = req_body.Timezone
+	contact.Lat = req_body.Lat
+	contact.Lon = req_body.Lon
+
+	return contact.Validate()
+}
+
+// responseContactError answers 404 for an unknown contact, 409 for a number already in the directory and 500 otherwise
+func responseContactError(c *fiber.Ctx, action string, err error) error {
+	switch {
+	case errors.Is(err, contacts.ErrNotFound):
+		return utils.ResponseError(c, fiber.StatusNotFound, "Contact not found")
+	case errors.Is(err, contacts.ErrNumberExists):
+		return utils.ResponseError(c, fiber.StatusConflict, err.Error())
+	}
+
+	return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to "+action+" contact: "+err.Error())
+}
+
+type contactsHandler struct {
+	store *contacts.Store
+}
+
+func NewContactsHandler(store *contacts.Store) *contactsHandler {
+	return &contactsHandler{
+		store: store,
+	}
+}
+
+// ListContacts godoc
+//
+//	@Summary		List contacts
+//	@Description	List the contacts of the directory ordered by name
+//	@Tags			Contacts
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			tag		query		string	false	"filter by tag"
+//	@Param			search	query		string	false	"search in the name and number"
+//	@Param			page	query		int		false	"page number"		default(1)
+//	@Param			limit	query		int		false	"items per page"	default(20)
+//	@Success		200		{object}	handlers.ContactsResponse
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/contacts [get]
+func (h *contactsHandler) ListContacts(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	contact_list, err := h.store.List(c.UserContext(), c.Query("tag"), c.Query("search"), limit, (page-1)*limit)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get contacts: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Contacts", contact_list)
+}
+
+// GetContact godoc
+//
+//	@Summary		Get contact
+//	@Description	Get one contact of the directory
+//	@Tags			Contacts
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"contact id"
+//	@Success		200	{object}	handlers.ContactResponse
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/contacts/{id} [get]
+func (h *contactsHandler) GetContact(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid contact id")
+	}
+
+	contact, err := h.store.Get(c.UserContext(), int64(id))
+	if err != nil {
+		return responseContactError(c, "get", err)
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Contact", contact)
+}
+
+// CreateContact godoc
+//
+//	@Summary		Create contact
+//	@Description	Add a contact to the directory, send endpoints can then target it with contact_ids or one of its tags
+//	@Tags			Contacts
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.ContactReq	true	"body request detail"
+//	@Success		201		{object}	handlers.ContactResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		409		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/contacts [post]
+func (h *contactsHandler) CreateContact(c *fiber.Ctx) error {
+	req_body := new(models.ContactReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	var contact contacts.Contact
+	if err := contactFromReq(req_body, &contact); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	contact, err := h.store.Create(c.UserContext(), contact)
+	if err != nil {
+		return responseContactError(c, "create", err)
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusCreated, "Contact created", contact)
+}
+
+// UpdateContact godoc
+//
+//	@Summary		Update contact
+//	@Description	Replace a contact of the directory
+//	@Tags			Contacts
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int					true	"contact id"
+//	@Param			request	body		models.ContactReq	true	"body request detail"
+//	@Success		200		{object}	handlers.ContactResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		409		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/contacts/{id} [put]
+func (h *contactsHandler) UpdateContact(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid contact id")
+	}
+
+	req_body := new(models.ContactReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	contact := contacts.Contact{ID: int64(id)}
+	if err := contactFromReq(req_body, &contact); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	contact, err = h.store.Update(c.UserContext(), contact)
+	if err != nil {
+		return responseContactError(c, "update", err)
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Contact updated", contact)
+}
+
+// DeleteContact godoc
+//
+//	@Summary		Delete contact
+//	@Description	Remove a contact from the directory
+//	@Tags			Contacts
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"contact id"
+//	@Success		200	{object}	utils.MessageResponseSuccess
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/contacts/{id} [delete]
+func (h *contactsHandler) DeleteContact(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid contact id")
+	}
+
+	if err := h.store.Delete(c.UserContext(), int64(id)); err != nil {
+		return responseContactError(c, "delete", err)
+	}
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Contact deleted")
+}
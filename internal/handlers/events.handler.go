@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/auth"
+	"github.com/momokii/go-wa-notifier/internal/stream"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+// how often a comment is written to an idle stream, so proxies keep it open and closed clients are noticed
+const streamHeartbeat = 25 * time.Second
+
+// first event of every stream, the state of the session when the stream was opened
+const eventStatus = "status"
+
+// StatusEvent is the data of the status event
+type StatusEvent struct {
+	IsConnected bool   `json:"is_connected"`
+	QRReady     bool   `json:"qr_ready"`
+	QRCode      string `json:"qr_code,omitempty"` // admins only
+}
+
+type eventsHandler struct {
+	hub      *stream.Hub
+	sessions *whatsapp.Manager
+}
+
+func NewEventsHandler(hub *stream.Hub, sessions *whatsapp.Manager) *eventsHandler {
+	return &eventsHandler{
+		hub:      hub,
+		sessions: sessions,
+	}
+}
+
+// writeEvent writes one server-sent event with the event as json data
+func writeEvent(w *bufio.Writer, evt whatsapp.Event) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// StreamEvents godoc
+//
+//	@Summary		Stream whatsapp connection events
+//	@Description	Server-Sent Events stream of the session. It starts with a status event and then pushes qr, paired, connected, disconnected and logged_out as they happen. The QR code is only sent to admin keys and the web dashboard login
+//	@Tags			Whatsapp
+//	@Produce		text/event-stream
+//	@Security		ApiKeyAuth
+//	@Param			session	query		string	false	"whatsapp session, the default session when empty"
+//	@Success		200		{object}	whatsapp.Event
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/wa/events [get]
+func (h *eventsHandler) StreamEvents(c *fiber.Ctx) error {
+	session := whatsapp.SessionName(c.Query("session"))
+	waClient, err := h.sessions.Get(session)
+	if err != nil {
+		return responseSessionError(c, err)
+	}
+
+	// whoever scans the QR code takes the session over, so only admins get it
+	is_admin := auth.IsAdmin(c)
+
+	qrCode, qrReady := waClient.GetQRCode()
+	status := StatusEvent{
+		IsConnected: waClient.IsConnected(),
+		QRReady:     qrReady,
+	}
+	if is_admin && qrReady {
+		status.QRCode = qrCode
+	}
+
+	events, unsubscribe := h.hub.Subscribe(session)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		if err := writeEvent(w, whatsapp.Event{Session: session, Type: eventStatus, Timestamp: time.Now(), Data: status}); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case evt := <-events:
+				if evt.Type == whatsapp.EventQR && !is_admin {
+					evt.Data = nil
+				}
+
+				if err := writeEvent(w, evt); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/jobs"
+	"github.com/momokii/go-wa-notifier/internal/notifier"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+type jobsHandler struct {
+	jobs     *jobs.Manager
+	notifier *notifier.Notifier
+}
+
+func NewJobsHandler(jobManager *jobs.Manager, notifierService *notifier.Notifier) *jobsHandler {
+	return &jobsHandler{
+		jobs:     jobManager,
+		notifier: notifierService,
+	}
+}
+
+// GetJob godoc
+//
+//	@Summary		Get async job
+//	@Description	Get the progress of an async broadcast, the pipeline stage and the latest outcome of every recipient
+//	@Tags			Jobs
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"job id"
+//	@Success		200	{object}	handlers.JobResponse
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/jobs/{id} [get]
+func (h *jobsHandler) GetJob(c *fiber.Ctx) error {
+	job, ok := h.jobs.Get(c.Params("id"))
+	if !ok {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Job not found")
+	}
+
+	// the job only saw the first attempt, reload the outbox so retries done since are visible
+	if len(job.Results) > 0 {
+		results, err := h.notifier.Results(c.UserContext(), job.Results)
+		if err != nil {
+			log.Println("Failed to refresh job results: " + err.Error())
+		} else {
+			job.Results = results
+		}
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Job status", job)
+}
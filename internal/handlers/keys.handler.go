@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/auth"
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// IssuedAPIKey is a new key with its raw value, the value is only shown once
+type IssuedAPIKey struct {
+	auth.APIKey
+	Key string `json:"key" example:"wan_3f9a1c0d6e2b4f8a9c1d2e3f4a5b6c7d8e9f0a1b2c3d4e5f"`
+}
+
+// for swagger docs
+type APIKeyResponse struct {
+	Error   bool        `json:"error" example:"false"`
+	Message string      `json:"message"`
+	Data    auth.APIKey `json:"data"`
+}
+
+// for swagger docs
+type IssuedAPIKeyResponse struct {
+	Error   bool         `json:"error" example:"false"`
+	Message string       `json:"message"`
+	Data    IssuedAPIKey `json:"data"`
+}
+
+// for swagger docs
+type APIKeysResponse struct {
+	Error   bool          `json:"error" example:"false"`
+	Message string        `json:"message"`
+	Data    []auth.APIKey `json:"data"`
+}
+
+// for swagger docs
+type AuditLogResponse struct {
+	Error   bool              `json:"error" example:"false"`
+	Message string            `json:"message"`
+	Data    []auth.AuditEntry `json:"data"`
+}
+
+type keysHandler struct {
+	store *auth.Store
+}
+
+func NewKeysHandler(store *auth.Store) *keysHandler {
+	return &keysHandler{
+		store: store,
+	}
+}
+
+// ListKeys godoc
+//
+//	@Summary		List api keys
+//	@Description	List every api key newest first, revoked keys included. The key values are never returned
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	handlers.APIKeysResponse
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/keys [get]
+func (h *keysHandler) ListKeys(c *fiber.Ctx) error {
+	keys, err := h.store.List(c.UserContext())
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get api keys: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "API keys", keys)
+}
+
+// CreateKey godoc
+//
+//	@Summary		Issue api key
+//	@Description	Issue a new api key with the scopes, the key value is only in this response so store it right away
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.APIKeyReq	true	"body request detail"
+//	@Success		201		{object}	handlers.IssuedAPIKeyResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/keys [post]
+func (h *keysHandler) CreateKey(c *fiber.Ctx) error {
+	req_body := new(models.APIKeyReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	req_body.Name = strings.TrimSpace(req_body.Name)
+	if req_body.Name == "" {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Name is required")
+	}
+
+	if err := auth.ValidateScopes(req_body.Scopes); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	key, raw, err := h.store.Create(c.UserContext(), req_body.Name, req_body.Scopes)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to create api key: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusCreated, "API key created, store the key now because it is not shown again", IssuedAPIKey{APIKey: key, Key: raw})
+}
+
+// RevokeKey godoc
+//
+//	@Summary		Revoke api key
+//	@Description	Revoke an api key, it can not be used anymore but stays in the list and the audit log
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"api key id"
+//	@Success		200	{object}	handlers.APIKeyResponse
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/keys/{id} [delete]
+func (h *keysHandler) RevokeKey(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid api key id")
+	}
+
+	key, err := h.store.Revoke(c.UserContext(), int64(id))
+	if errors.Is(err, auth.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "API key not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to revoke api key: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "API key revoked", key)
+}
+
+// ListAuditLog godoc
+//
+//	@Summary		List audit log
+//	@Description	List the api calls newest first with the key that made them
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			key_id	query		int	false	"filter by api key id"
+//	@Param			page	query		int	false	"page number"		default(1)
+//	@Param			limit	query		int	false	"items per page"	default(20)
+//	@Success		200		{object}	handlers.AuditLogResponse
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/audit [get]
+func (h *keysHandler) ListAuditLog(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	entries, err := h.store.ListAudit(c.UserContext(), int64(c.QueryInt("key_id", 0)), limit, (page-1)*limit)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get audit log: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Audit log", entries)
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+var (
+	messageStates = []string{
+		outbox.StatusPending, outbox.StatusSending, outbox.StatusSent,
+		whatsapp.ReceiptDelivered, whatsapp.ReceiptRead, whatsapp.ReceiptPlayed, outbox.StatusDead,
+	}
+	messageBroadcasts = []string{
+		outbox.BroadcastNews, outbox.BroadcastWeather, outbox.BroadcastCustom, outbox.BroadcastMedia, outbox.BroadcastReply,
+	}
+)
+
+// rows read per query while exporting, the export streams every page until the filter is exhausted
+const exportBatchSize = 500
+
+// MessagesPage is one page of the message history, pass next_cursor as cursor to get the next page
+type MessagesPage struct {
+	Messages   []outbox.Message `json:"messages"`
+	NextCursor int64            `json:"next_cursor,omitempty" example:"120"` // empty on the last page
+}
+
+// for swagger docs
+type MessagesPageResponse struct {
+	Error   bool         `json:"error" example:"false"`
+	Message string       `json:"message"`
+	Data    MessagesPage `json:"data"`
+}
+
+// parseDate reads a date like 2025-04-01 or a RFC3339 time, a plain date as the end of a range includes the whole day
+func parseDate(value string, end bool) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		if end {
+			date = date.AddDate(0, 0, 1)
+		}
+		return date, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// messageFilter reads the history filters shared by the listing and the export from the query
+func messageFilter(c *fiber.Ctx) (outbox.Filter, error) {
+	filter := outbox.Filter{
+		Session:   c.Query("session"),
+		Recipient: c.Query("number"),
+		Broadcast: c.Query("type"),
+		State:     c.Query("status"),
+	}
+
+	if filter.Broadcast != "" && !slices.Contains(messageBroadcasts, filter.Broadcast) {
+		return filter, fmt.Errorf("type must be one of news, weather, custom, media, reply")
+	}
+
+	if filter.State != "" && !slices.Contains(messageStates, filter.State) {
+		return filter, fmt.Errorf("status must be one of pending, sending, sent, delivered, read, played, dead")
+	}
+
+	// recipients are stored normalized, so 0857... finds 62857...
+	if filter.Recipient != "" {
+		if check := whatsapp.NormalizeRecipients([]string{filter.Recipient})[0]; check.Valid {
+			filter.Recipient = check.Number
+		}
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = parseDate(from, false); err != nil {
+			return filter, fmt.Errorf("invalid from, use a date like 2025-04-01 or a RFC3339 time")
+		}
+	}
+
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = parseDate(to, true); err != nil {
+			return filter, fmt.Errorf("invalid to, use a date like 2025-04-30 or a RFC3339 time")
+		}
+	}
+
+	return filter, nil
+}
+
+type messagesHandler struct {
+	store *outbox.Store
+}
+
+func NewMessagesHandler(store *outbox.Store) *messagesHandler {
+	return &messagesHandler{
+		store: store,
+	}
+}
+
+// ListMessages godoc
+//
+//	@Summary		List message history
+//	@Description	List every outbound message newest first with its origin and delivery state, the state moves from sent to delivered, read and played with the receipts of the recipient
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			session	query		string	false	"filter by whatsapp session"
+//	@Param			number	query		string	false	"filter by recipient number or group JID"
+//	@Param			type	query		string	false	"filter by broadcast type"	Enums(news, weather, custom, media, reply)
+//	@Param			status	query		string	false	"filter by status"			Enums(pending, sending, sent, delivered, read, played, dead)
+//	@Param			from	query		string	false	"created at or after, a date like 2025-04-01 or a RFC3339 time"
+//	@Param			to		query		string	false	"created before, a plain date includes the whole day"
+//	@Param			cursor	query		int		false	"next_cursor of the previous page"
+//	@Param			limit	query		int		false	"items per page"	default(20)
+//	@Success		200		{object}	handlers.MessagesPageResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/messages [get]
+func (h *messagesHandler) ListMessages(c *fiber.Ctx) error {
+	filter, err := messageFilter(c)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	filter.Before = int64(c.QueryInt("cursor", 0))
+	if filter.Before < 0 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	messages, err := h.store.History(c.UserContext(), filter, limit)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get messages: "+err.Error())
+	}
+
+	page := MessagesPage{Messages: messages}
+	if len(messages) == limit {
+		page.NextCursor = messages[len(messages)-1].ID
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Messages", page)
+}
+
+// ExportMessages godoc
+//
+//	@Summary		Export message history
+//	@Description	Download every message that matches the filters as CSV or JSON Lines, newest first
+//	@Tags			Messages
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Security		ApiKeyAuth
+//	@Param			format	query		string	false	"file format"	Enums(csv, jsonl)	default(csv)
+//	@Param			session	query		string	false	"filter by whatsapp session"
+//	@Param			number	query		string	false	"filter by recipient number or group JID"
+//	@Param			type	query		string	false	"filter by broadcast type"	Enums(news, weather, custom, media, reply)
+//	@Param			status	query		string	false	"filter by status"			Enums(pending, sending, sent, delivered, read, played, dead)
+//	@Param			from	query		string	false	"created at or after, a date like 2025-04-01 or a RFC3339 time"
+//	@Param			to		query		string	false	"created before, a plain date includes the whole day"
+//	@Success		200		{file}		file
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Router			/messages/export [get]
+func (h *messagesHandler) ExportMessages(c *fiber.Ctx) error {
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Format must be csv or jsonl")
+	}
+
+	filter, err := messageFilter(c)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	content_type := "text/csv"
+	if format == "jsonl" {
+		content_type = "application/x-ndjson"
+	}
+	c.Attachment(fmt.Sprintf("messages-%s.%s", time.Now().Format("20060102-150405"), format))
+	c.Set(fiber.HeaderContentType, content_type)
+
+	// the writer runs after the handler returned, so it can not use the request context
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		if err := h.export(ctx, w, format, filter); err != nil {
+			log.Println("Failed to export messages: " + err.Error())
+		}
+	})
+
+	return nil
+}
+
+// export writes the messages of the filter page by page
+func (h *messagesHandler) export(ctx context.Context, w *bufio.Writer, format string, filter outbox.Filter) error {
+	defer w.Flush()
+
+	csv_writer := csv.NewWriter(w)
+	if format == "csv" {
+		if err := csv_writer.Write([]string{
+			"id", "created_at", "session", "type", "source", "source_ref", "using_llm", "number", "message_type", "body",
+			"status", "attempts", "last_error", "wa_message_id", "sent_at", "delivered_at", "read_at", "played_at",
+		}); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	for {
+		messages, err := h.store.History(ctx, filter, exportBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range messages {
+			if format == "jsonl" {
+				if err := encoder.Encode(msg); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := csv_writer.Write([]string{
+				strconv.FormatInt(msg.ID, 10), msg.CreatedAt.Format(time.RFC3339), msg.Session, msg.Broadcast, msg.Source, msg.SourceRef,
+				strconv.FormatBool(msg.UsingLLM), msg.Recipient, msg.Type, msg.Body, msg.State, strconv.Itoa(msg.Attempts), msg.LastError,
+				msg.WAMessageID, formatTime(msg.SentAt), formatTime(msg.DeliveredAt), formatTime(msg.ReadAt), formatTime(msg.PlayedAt),
+			}); err != nil {
+				return err
+			}
+		}
+
+		csv_writer.Flush()
+		if err := csv_writer.Error(); err != nil {
+			return err
+		}
+
+		if len(messages) < exportBatchSize {
+			return nil
+		}
+		filter.Before = messages[len(messages)-1].ID
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// GetMessage godoc
+//
+//	@Summary		Get message
+//	@Description	Get one sent message with its origin, delivery state and the time of every receipt, the id is the outbox_id of the send result
+//	@Tags			Messages
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"message id"
+//	@Success		200	{object}	handlers.OutboxMessageResponse
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/messages/{id} [get]
+func (h *messagesHandler) GetMessage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid message id")
+	}
+
+	msg, err := h.store.Get(c.UserContext(), int64(id))
+	if errors.Is(err, outbox.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Message not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get message: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Message", msg)
+}
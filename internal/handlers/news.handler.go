@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/notifier"
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// for swagger docs
+type NewsSourcesResponse struct {
+	Error   bool             `json:"error" example:"false"`
+	Message string           `json:"message"`
+	Data    []newsapi.Source `json:"data"`
+}
+
+type newsHandler struct {
+	notifier *notifier.Notifier
+}
+
+func NewNewsHandler(notifierService *notifier.Notifier) *newsHandler {
+	return &newsHandler{
+		notifier: notifierService,
+	}
+}
+
+// ListNewsSources godoc
+//
+//	@Summary		List news sources
+//	@Description	List the NewsAPI sources, their ids can be used as sources of /wa/news, /wa/news/search and the watchlists
+//	@Tags			News
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			category	query		string	false	"category of the sources"	Enums(business, entertainment, general, health, science, sports, technology)
+//	@Param			language	query		string	false	"ISO 639-1 code of the language of the sources, like en"
+//	@Param			country		query		string	false	"ISO 3166-1 code of the country of the sources, like us or id"
+//	@Success		200			{object}	handlers.NewsSourcesResponse
+//	@Failure		400			{object}	utils.MessageResponseError
+//	@Failure		401			{object}	utils.MessageResponseError
+//	@Failure		403			{object}	utils.MessageResponseError
+//	@Failure		500			{object}	utils.MessageResponseError
+//	@Router			/news/sources [get]
+func (h *newsHandler) ListNewsSources(c *fiber.Ctx) error {
+	sources, err := h.notifier.NewsSources(newsapi.NewsAPISourcesReq{
+		Category: c.Query("category"),
+		Language: c.Query("language"),
+		Country:  c.Query("country"),
+	})
+	if err != nil {
+		return responseBuildError(c, err)
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "News sources", sources)
+}
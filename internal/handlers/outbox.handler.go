@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// for swagger docs
+type OutboxMessageResponse struct {
+	Error   bool           `json:"error" example:"false"`
+	Message string         `json:"message"`
+	Data    outbox.Message `json:"data"`
+}
+
+// for swagger docs
+type OutboxMessagesResponse struct {
+	Error   bool             `json:"error" example:"false"`
+	Message string           `json:"message"`
+	Data    []outbox.Message `json:"data"`
+}
+
+type outboxHandler struct {
+	outbox *outbox.Outbox
+}
+
+func NewOutboxHandler(outboxQueue *outbox.Outbox) *outboxHandler {
+	return &outboxHandler{
+		outbox: outboxQueue,
+	}
+}
+
+// ListOutbox godoc
+//
+//	@Summary		List outbox messages
+//	@Description	List outbound messages newest first, use status=dead to inspect the dead-letter messages
+//	@Tags			Outbox
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			status	query		string	false	"filter by status"	Enums(pending, sending, sent, dead)
+//	@Param			session	query		string	false	"filter by whatsapp session"
+//	@Param			page	query		int		false	"page number"		default(1)
+//	@Param			limit	query		int		false	"items per page"	default(20)
+//	@Success		200		{object}	handlers.OutboxMessagesResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/outbox [get]
+func (h *outboxHandler) ListOutbox(c *fiber.Ctx) error {
+	status := c.Query("status")
+	if status != "" && status != outbox.StatusPending && status != outbox.StatusSending && status != outbox.StatusSent && status != outbox.StatusDead {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Status must be one of pending, sending, sent, dead")
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	messages, err := h.outbox.Store().List(c.UserContext(), status, c.Query("session"), limit, (page-1)*limit)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get outbox messages: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Outbox messages", messages)
+}
+
+// GetOutboxMessage godoc
+//
+//	@Summary		Get outbox message
+//	@Description	Get one outbound message with its attempts and last error
+//	@Tags			Outbox
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"outbox message id"
+//	@Success		200	{object}	handlers.OutboxMessageResponse
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/outbox/{id} [get]
+func (h *outboxHandler) GetOutboxMessage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid message id")
+	}
+
+	msg, err := h.outbox.Store().Get(c.UserContext(), int64(id))
+	if errors.Is(err, outbox.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Message not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get message: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Outbox message", msg)
+}
+
+// ReplayOutboxMessage godoc
+//
+//	@Summary		Replay dead message
+//	@Description	Put a dead-letter message back in the outbox with a fresh set of attempts
+//	@Tags			Outbox
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"outbox message id"
+//	@Success		200	{object}	handlers.OutboxMessageResponse
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/outbox/{id}/replay [post]
+func (h *outboxHandler) ReplayOutboxMessage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid message id")
+	}
+
+	msg, err := h.outbox.Replay(c.UserContext(), int64(id))
+	if errors.Is(err, outbox.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Dead message not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to replay message: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Message queued again", msg)
+}
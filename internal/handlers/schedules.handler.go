@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/internal/scheduler"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// for swagger docs
+type ScheduleResponse struct {
+	Error   bool               `json:"error" example:"false"`
+	Message string             `json:"message"`
+	Data    scheduler.Schedule `json:"data"`
+}
+
+// for swagger docs
+type SchedulesResponse struct {
+	Error   bool                 `json:"error" example:"false"`
+	Message string               `json:"message"`
+	Data    []scheduler.Schedule `json:"data"`
+}
+
+// for swagger docs
+type ScheduleRunsResponse struct {
+	Error   bool            `json:"error" example:"false"`
+	Message string          `json:"message"`
+	Data    []scheduler.Run `json:"data"`
+}
+
+// scheduleFromReq fills the schedule with the request body and validates it
+func scheduleFromReq(req_body *models.ScheduleReq, sch *scheduler.Schedule) error {
+	sch.Name = req_body.Name
+	sch.Cron = req_body.Cron
+	sch.Timezone = req_body.Timezone
+	sch.JobType = req_body.JobType
+	sch.Category = req_body.Category
+	sch.Country = req_body.Country
+	sch.Sources = req_body.Sources
+	sch.FeedURL = req_body.FeedURL
+	sch.WeatherType = req_body.WeatherType
+	sch.Lat = req_body.Lat
+	sch.Lon = req_body.Lon
+	sch.Messages = req_body.Messages
+	sch.WhatsappNumbers = req_body.WhatsappNumbers
+	sch.Session = req_body.Session
+	sch.Subscribers = req_body.Subscribers
+	sch.UsingLLM = req_body.UsingLLM
+	sch.Typing = req_body.Typing
+
+	sch.Enabled = true
+	if req_body.Enabled != nil {
+		sch.Enabled = *req_body.Enabled
+	}
+
+	return sch.Validate()
+}
+
+type schedulesHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewSchedulesHandler(sched *scheduler.Scheduler) *schedulesHandler {
+	return &schedulesHandler{
+		scheduler: sched,
+	}
+}
+
+// reload applies the saved change to the running cron, the change is already stored so only log a failure
+func (h *schedulesHandler) reload(c *fiber.Ctx) {
+	if err := h.scheduler.Reload(c.UserContext()); err != nil {
+		log.Println("Failed to reload schedules: " + err.Error())
+	}
+}
+
+// ListSchedules godoc
+//
+//	@Summary		List schedules
+//	@Description	List every recurring broadcast with its next run time
+//	@Tags			Schedules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	handlers.SchedulesResponse
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/schedules [get]
+func (h *schedulesHandler) ListSchedules(c *fiber.Ctx) error {
+	schedules, err := h.scheduler.Store().List(c.UserContext())
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get schedules: "+err.Error())
+	}
+
+	for i := range schedules {
+		h.scheduler.NextRun(&schedules[i])
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Schedules", schedules)
+}
+
+// GetSchedule godoc
+//
+//	@Summary		Get schedule
+//	@Description	Get one recurring broadcast with its next run time
+//	@Tags			Schedules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"schedule id"
+//	@Success		200	{object}	handlers.ScheduleResponse
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/schedules/{id} [get]
+func (h *schedulesHandler) GetSchedule(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid schedule id")
+	}
+
+	sch, err := h.scheduler.Store().Get(c.UserContext(), int64(id))
+	if errors.Is(err, scheduler.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Schedule not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get schedule: "+err.Error())
+	}
+
+	h.scheduler.NextRun(&sch)
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Schedule", sch)
+}
+
+// CreateSchedule godoc
+//
+//	@Summary		Create schedule
+//	@Description	Create a recurring news, weather, custom message or RSS/Atom feed broadcast
+//	@Tags			Schedules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.ScheduleReq	true	"body request detail"
+//	@Success		201		{object}	handlers.ScheduleResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/schedules [post]
+func (h *schedulesHandler) CreateSchedule(c *fiber.Ctx) error {
+	req_body := new(models.ScheduleReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	var sch scheduler.Schedule
+	if err := scheduleFromReq(req_body, &sch); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	sch, err := h.scheduler.Store().Create(c.UserContext(), sch)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to create schedule: "+err.Error())
+	}
+
+	h.reload(c)
+	h.scheduler.NextRun(&sch)
+
+	return utils.ResponseWitData(c, fiber.StatusCreated, "Schedule created", sch)
+}
+
+// UpdateSchedule godoc
+//
+//	@Summary		Update schedule
+//	@Description	Replace a recurring broadcast, the new version is used from its next run
+//	@Tags			Schedules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int					true	"schedule id"
+//	@Param			request	body		models.ScheduleReq	true	"body request detail"
+//	@Success		200		{object}	handlers.ScheduleResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/schedules/{id} [put]
+func (h *schedulesHandler) UpdateSchedule(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid schedule id")
+	}
+
+	req_body := new(models.ScheduleReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	sch := scheduler.Schedule{ID: int64(id)}
+	if err := scheduleFromReq(req_body, &sch); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	sch, err = h.scheduler.Store().Update(c.UserContext(), sch)
+	if errors.Is(err, scheduler.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Schedule not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to update schedule: "+err.Error())
+	}
+
+	h.reload(c)
+	h.scheduler.NextRun(&sch)
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Schedule updated", sch)
+}
+
+// DeleteSchedule godoc
+//
+//	@Summary		Delete schedule
+//	@Description	Delete a recurring broadcast together with its run log
+//	@Tags			Schedules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"schedule id"
+//	@Success		200	{object}	utils.MessageResponseSuccess
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/schedules/{id} [delete]
+func (h *schedulesHandler) DeleteSchedule(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid schedule id")
+	}
+
+	err = h.scheduler.Store().Delete(c.UserContext(), int64(id))
+	if errors.Is(err, scheduler.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Schedule not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to delete schedule: "+err.Error())
+	}
+
+	h.reload(c)
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Schedule deleted")
+}
+
+// ListScheduleRuns godoc
+//
+//	@Summary		List schedule runs
+//	@Description	List the run log of a schedule newest first, with the outcome of every recipient
+//	@Tags			Schedules
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int	true	"schedule id"
+//	@Param			page	query		int	false	"page number"		default(1)
+//	@Param			limit	query		int	false	"items per page"	default(20)
+//	@Success		200		{object}	handlers.ScheduleRunsResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/schedules/{id}/runs [get]
+func (h *schedulesHandler) ListScheduleRuns(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid schedule id")
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	runs, err := h.scheduler.Store().ListRuns(c.UserContext(), int64(id), limit, (page-1)*limit)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get schedule runs: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Schedule runs", runs)
+}
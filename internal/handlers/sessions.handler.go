@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+// for swagger docs
+type WASessionResponse struct {
+	Error   bool                   `json:"error" example:"false"`
+	Message string                 `json:"message"`
+	Data    models.WhatsappSession `json:"data"`
+}
+
+// for swagger docs
+type WASessionsResponse struct {
+	Error   bool                     `json:"error" example:"false"`
+	Message string                   `json:"message"`
+	Data    []models.WhatsappSession `json:"data"`
+}
+
+// for swagger docs
+type WASessionPairResponse struct {
+	Error   bool                       `json:"error" example:"false"`
+	Message string                     `json:"message"`
+	Data    models.WhatsappSessionPair `json:"data"`
+}
+
+func sessionFromInfo(info whatsapp.SessionInfo) models.WhatsappSession {
+	return models.WhatsappSession{
+		Name:        info.Name,
+		JID:         info.JID,
+		IsConnected: info.IsConnected,
+		IsLoggedIn:  info.IsLoggedIn,
+		QRReady:     info.QRReady,
+		CreatedAt:   info.CreatedAt,
+	}
+}
+
+type sessionsHandler struct {
+	sessions *whatsapp.Manager
+}
+
+func NewSessionsHandler(sessions *whatsapp.Manager) *sessionsHandler {
+	return &sessionsHandler{
+		sessions: sessions,
+	}
+}
+
+// ListSessions godoc
+//
+//	@Summary		List whatsapp sessions
+//	@Description	List every WhatsApp account of the server with its connection state
+//	@Tags			Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	handlers.WASessionsResponse
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/wa/sessions [get]
+func (h *sessionsHandler) ListSessions(c *fiber.Ctx) error {
+	sessions_info, err := h.sessions.List(c.UserContext())
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get sessions: "+err.Error())
+	}
+
+	sessions := make([]models.WhatsappSession, 0, len(sessions_info))
+	for _, info := range sessions_info {
+		sessions = append(sessions, sessionFromInfo(info))
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "WhatsApp Sessions", sessions)
+}
+
+// GetSession godoc
+//
+//	@Summary		Get whatsapp session
+//	@Description	Get one WhatsApp session with its connection state
+//	@Tags			Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			name	path		string	true	"session name"
+//	@Success		200		{object}	handlers.WASessionResponse
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/wa/sessions/{name} [get]
+func (h *sessionsHandler) GetSession(c *fiber.Ctx) error {
+	info, err := h.sessions.Info(c.UserContext(), c.Params("name"))
+	if errors.Is(err, whatsapp.ErrSessionNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Session not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get session: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "WhatsApp Session", sessionFromInfo(info))
+}
+
+// CreateSession godoc
+//
+//	@Summary		Create whatsapp session
+//	@Description	Create a new WhatsApp session and start its QR login, get the QR code from /wa/sessions/{name}/pair
+//	@Tags			Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.WhatsappSessionReq	true	"body request detail"
+//	@Success		201		{object}	handlers.WASessionResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		409		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/wa/sessions [post]
+func (h *sessionsHandler) CreateSession(c *fiber.Ctx) error {
+	req_body := new(models.WhatsappSessionReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if req_body.Name == "" {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Name is required")
+	}
+
+	if _, err := h.sessions.Create(c.UserContext(), req_body.Name); err != nil {
+		if errors.Is(err, whatsapp.ErrSessionExists) {
+			return utils.ResponseError(c, fiber.StatusConflict, err.Error())
+		}
+
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Failed to create session: "+err.Error())
+	}
+
+	info, err := h.sessions.Info(c.UserContext(), req_body.Name)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get session: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusCreated, "WhatsApp Session created", sessionFromInfo(info))
+}
+
+// PairSession godoc
+//
+//	@Summary		Pair whatsapp session
+//	@Description	Start the session if needed and return its current QR code, scan it from WhatsApp > Linked devices. Call it again to get the rotated code until is_logged_in is true
+//	@Tags			Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			name	path		string	true	"session name"
+//	@Success		200		{object}	handlers.WASessionPairResponse
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/wa/sessions/{name}/pair [post]
+func (h *sessionsHandler) PairSession(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	waClient, err := h.sessions.Get(name)
+	if err != nil {
+		return responseSessionError(c, err)
+	}
+
+	qrCode, qrReady := waClient.GetQRCode()
+	pair := models.WhatsappSessionPair{
+		Name:       name,
+		IsLoggedIn: waClient.GetClient().Store.ID != nil,
+		QRReady:    qrReady,
+	}
+	if qrReady {
+		pair.QRCode = qrCode
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "WhatsApp Session pairing", pair)
+}
+
+// DeleteSession godoc
+//
+//	@Summary		Delete whatsapp session
+//	@Description	Log the session out, remove its device and delete it. The default session can only be logged out
+//	@Tags			Sessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			name	path		string	true	"session name"
+//	@Success		200		{object}	utils.MessageResponseSuccess
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/wa/sessions/{name} [delete]
+func (h *sessionsHandler) DeleteSession(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if name == whatsapp.DefaultSession {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "The default session can not be deleted, log it out instead")
+	}
+
+	if err := h.sessions.Delete(c.UserContext(), name); err != nil {
+		if errors.Is(err, whatsapp.ErrSessionNotFound) {
+			return utils.ResponseError(c, fiber.StatusNotFound, "Session not found")
+		}
+
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to delete session: "+err.Error())
+	}
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Session deleted")
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/subscriptions"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// for swagger docs
+type SubscriptionsResponse struct {
+	Error   bool                         `json:"error" example:"false"`
+	Message string                       `json:"message"`
+	Data    []subscriptions.Subscription `json:"data"`
+}
+
+type subscriptionsHandler struct {
+	store *subscriptions.Store
+}
+
+func NewSubscriptionsHandler(store *subscriptions.Store) *subscriptionsHandler {
+	return &subscriptionsHandler{
+		store: store,
+	}
+}
+
+// ListSubscriptions godoc
+//
+//	@Summary		List subscriptions
+//	@Description	List the subscriptions recipients made themselves with the bot commands like !subscribe news technology
+//	@Tags			Subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			session	query		string	false	"filter by whatsapp session"
+//	@Param			number	query		string	false	"filter by number"
+//	@Param			topic	query		string	false	"filter by topic"	Enums(news, weather)
+//	@Success		200		{object}	handlers.SubscriptionsResponse
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/subscriptions [get]
+func (h *subscriptionsHandler) ListSubscriptions(c *fiber.Ctx) error {
+	subs, err := h.store.List(c.UserContext(), c.Query("session"), c.Query("number"), c.Query("topic"))
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get subscriptions: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Subscriptions", subs)
+}
+
+// DeleteSubscription godoc
+//
+//	@Summary		Delete subscription
+//	@Description	Remove a subscription on behalf of the recipient
+//	@Tags			Subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"subscription id"
+//	@Success		200	{object}	utils.MessageResponseSuccess
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/subscriptions/{id} [delete]
+func (h *subscriptionsHandler) DeleteSubscription(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid subscription id")
+	}
+
+	if err := h.store.Delete(c.UserContext(), int64(id)); err != nil {
+		if errors.Is(err, subscriptions.ErrNotFound) {
+			return utils.ResponseError(c, fiber.StatusNotFound, "Subscription not found")
+		}
+
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to delete subscription: "+err.Error())
+	}
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Subscription deleted")
+}
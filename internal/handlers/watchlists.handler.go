@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/internal/watchlists"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// for swagger docs
+type WatchlistResponse struct {
+	Error   bool                 `json:"error" example:"false"`
+	Message string               `json:"message"`
+	Data    watchlists.Watchlist `json:"data"`
+}
+
+// for swagger docs
+type WatchlistsResponse struct {
+	Error   bool                   `json:"error" example:"false"`
+	Message string                 `json:"message"`
+	Data    []watchlists.Watchlist `json:"data"`
+}
+
+// for swagger docs
+type WatchlistArticlesResponse struct {
+	Error   bool                 `json:"error" example:"false"`
+	Message string               `json:"message"`
+	Data    []watchlists.Article `json:"data"`
+}
+
+// for swagger docs
+type WatchlistPollResponse struct {
+	Error   bool                  `json:"error" example:"false"`
+	Message string                `json:"message"`
+	Data    watchlists.PollResult `json:"data"`
+}
+
+// watchlistFromReq fills the watchlist with the request body and validates it
+func watchlistFromReq(req_body *models.WatchlistReq, w *watchlists.Watchlist) error {
+	w.Name = req_body.Name
+	w.Query = req_body.Query
+	w.SearchIn = req_body.SearchIn
+	w.Domains = req_body.Domains
+	w.ExcludeDomains = req_body.ExcludeDomains
+	w.Sources = req_body.Sources
+	w.Language = req_body.Language
+	w.WhatsappNumbers = req_body.WhatsappNumbers
+	w.Tags = req_body.Tags
+	w.Session = req_body.Session
+	w.IntervalMinutes = req_body.IntervalMinutes
+	w.Typing = req_body.Typing
+
+	w.Enabled = true
+	if req_body.Enabled != nil {
+		w.Enabled = *req_body.Enabled
+	}
+
+	if w.WhatsappNumbers == nil {
+		w.WhatsappNumbers = []string{}
+	}
+
+	return w.Validate()
+}
+
+type watchlistsHandler struct {
+	watcher *watchlists.Watcher
+}
+
+func NewWatchlistsHandler(watcher *watchlists.Watcher) *watchlistsHandler {
+	return &watchlistsHandler{
+		watcher: watcher,
+	}
+}
+
+// ListWatchlists godoc
+//
+//	@Summary		List watchlists
+//	@Description	List every keyword watchlist with the time of its last and next poll
+//	@Tags			Watchlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	handlers.WatchlistsResponse
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/watchlists [get]
+func (h *watchlistsHandler) ListWatchlists(c *fiber.Ctx) error {
+	list, err := h.watcher.Store().List(c.UserContext())
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get watchlists: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Watchlists", list)
+}
+
+// GetWatchlist godoc
+//
+//	@Summary		Get watchlist
+//	@Description	Get one keyword watchlist with the time of its last and next poll
+//	@Tags			Watchlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"watchlist id"
+//	@Success		200	{object}	handlers.WatchlistResponse
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/watchlists/{id} [get]
+func (h *watchlistsHandler) GetWatchlist(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid watchlist id")
+	}
+
+	w, err := h.watcher.Store().Get(c.UserContext(), int64(id))
+	if errors.Is(err, watchlists.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Watchlist not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get watchlist: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Watchlist", w)
+}
+
+// CreateWatchlist godoc
+//
+//	@Summary		Create watchlist
+//	@Description	Create a NewsAPI everything search that is polled on its interval, only articles it has not seen before are sent as a WhatsApp alert. The first poll runs within a minute and only remembers the articles already published
+//	@Tags			Watchlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.WatchlistReq	true	"body request detail"
+//	@Success		201		{object}	handlers.WatchlistResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/watchlists [post]
+func (h *watchlistsHandler) CreateWatchlist(c *fiber.Ctx) error {
+	req_body := new(models.WatchlistReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	var w watchlists.Watchlist
+	if err := watchlistFromReq(req_body, &w); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	w, err := h.watcher.Store().Create(c.UserContext(), w)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to create watchlist: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusCreated, "Watchlist created", w)
+}
+
+// UpdateWatchlist godoc
+//
+//	@Summary		Update watchlist
+//	@Description	Replace a keyword watchlist. When the search changes the next poll runs right away and only remembers the articles of the new search
+//	@Tags			Watchlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int					true	"watchlist id"
+//	@Param			request	body		models.WatchlistReq	true	"body request detail"
+//	@Success		200		{object}	handlers.WatchlistResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/watchlists/{id} [put]
+func (h *watchlistsHandler) UpdateWatchlist(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid watchlist id")
+	}
+
+	req_body := new(models.WatchlistReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	w := watchlists.Watchlist{ID: int64(id)}
+	if err := watchlistFromReq(req_body, &w); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	w, err = h.watcher.Store().Update(c.UserContext(), w)
+	if errors.Is(err, watchlists.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Watchlist not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to update watchlist: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Watchlist updated", w)
+}
+
+// DeleteWatchlist godoc
+//
+//	@Summary		Delete watchlist
+//	@Description	Delete a keyword watchlist together with its seen articles
+//	@Tags			Watchlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"watchlist id"
+//	@Success		200	{object}	utils.MessageResponseSuccess
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/watchlists/{id} [delete]
+func (h *watchlistsHandler) DeleteWatchlist(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid watchlist id")
+	}
+
+	err = h.watcher.Store().Delete(c.UserContext(), int64(id))
+	if errors.Is(err, watchlists.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Watchlist not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to delete watchlist: "+err.Error())
+	}
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Watchlist deleted")
+}
+
+// ListWatchlistArticles godoc
+//
+//	@Summary		List watchlist articles
+//	@Description	List the articles a watchlist has found, the latest first. Articles of the first poll are remembered without an alert
+//	@Tags			Watchlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int	true	"watchlist id"
+//	@Param			page	query		int	false	"page number"		default(1)
+//	@Param			limit	query		int	false	"items per page"	default(20)
+//	@Success		200		{object}	handlers.WatchlistArticlesResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/watchlists/{id}/articles [get]
+func (h *watchlistsHandler) ListWatchlistArticles(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid watchlist id")
+	}
+
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	articles, err := h.watcher.Store().Articles(c.UserContext(), int64(id), limit, (page-1)*limit)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get watchlist articles: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Watchlist articles", articles)
+}
+
+// PollWatchlist godoc
+//
+//	@Summary		Poll watchlist now
+//	@Description	Poll a watchlist right away instead of waiting for its interval, new articles are alerted like a regular poll and the next poll moves one interval ahead
+//	@Tags			Watchlists
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"watchlist id"
+//	@Success		200	{object}	handlers.WatchlistPollResponse
+//	@Failure		400	{object}	utils.MessageResponseError
+//	@Failure		401	{object}	utils.MessageResponseError
+//	@Failure		403	{object}	utils.MessageResponseError
+//	@Failure		404	{object}	utils.MessageResponseError
+//	@Failure		500	{object}	utils.MessageResponseError
+//	@Router			/watchlists/{id}/poll [post]
+func (h *watchlistsHandler) PollWatchlist(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id < 1 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid watchlist id")
+	}
+
+	w, err := h.watcher.Store().Get(c.UserContext(), int64(id))
+	if errors.Is(err, watchlists.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, "Watchlist not found")
+	}
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get watchlist: "+err.Error())
+	}
+
+	result, err := h.watcher.Poll(c.UserContext(), w)
+	if err != nil {
+		return responseBuildError(c, err)
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Watchlist polled", result)
+}
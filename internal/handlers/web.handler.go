@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/momokii/go-wa-notifier/internal/auth"
+)
+
+type webHandler struct {
+	login *auth.WebLogin
+}
+
+func NewWebHandler(login *auth.WebLogin) *webHandler {
+	return &webHandler{
+		login: login,
+	}
+}
+
+func (h *webHandler) renderLogin(c *fiber.Ctx, code int, message string) error {
+	if message == "" && !h.login.Enabled() {
+		message = auth.ErrLoginOff.Error()
+	}
+
+	return c.Status(code).Render("login", fiber.Map{
+		"Title":   "Go Whatsapp Notifier - Login",
+		"Message": message,
+	})
+}
+
+// LoginPage shows the login form, a logged in admin goes straight to the dashboard
+func (h *webHandler) LoginPage(c *fiber.Ctx) error {
+	if _, _, ok := h.login.Admin(c); ok {
+		return c.Redirect("/")
+	}
+
+	return h.renderLogin(c, fiber.StatusOK, "")
+}
+
+// Login checks the form credentials and starts the session
+func (h *webHandler) Login(c *fiber.Ctx) error {
+	err := h.login.Login(c, c.FormValue("username"), c.FormValue("password"))
+	switch {
+	case errors.Is(err, auth.ErrInvalidLogin):
+		return h.renderLogin(c, fiber.StatusUnauthorized, "Invalid username or password")
+	case errors.Is(err, auth.ErrLoginOff):
+		return h.renderLogin(c, fiber.StatusServiceUnavailable, err.Error())
+	case err != nil:
+		log.Println("Failed to start web session: " + err.Error())
+		return h.renderLogin(c, fiber.StatusInternalServerError, "Failed to log in, please try again")
+	}
+
+	return c.Redirect("/")
+}
+
+// Logout ends the session, it needs the csrf token so another site can not log the admin out
+func (h *webHandler) Logout(c *fiber.Ctx) error {
+	_, csrf, ok := h.login.Admin(c)
+	if !ok {
+		return c.Redirect("/login")
+	}
+
+	if !h.login.CheckCSRF(c, csrf) {
+		return fiber.NewError(fiber.StatusForbidden, "Invalid CSRF token")
+	}
+
+	if err := h.login.Logout(c); err != nil {
+		log.Println("Failed to end web session: " + err.Error())
+	}
+
+	return c.Redirect("/login")
+}
+
+// Dashboard shows the connection status and the pairing QR code, only for the logged in admin
+func (h *webHandler) Dashboard(c *fiber.Ctx) error {
+	user, csrf, _ := h.login.Admin(c)
+
+	return c.Render("status-wa", fiber.Map{
+		"Title":     "Go Whatsapp Notifier",
+		"Username":  user,
+		"CSRFToken": csrf,
+	})
+}
@@ -1,22 +1,38 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"net/http"
 	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/momokii/go-llmbridge/pkg/openai"
+	"github.com/momokii/go-wa-notifier/internal/auth"
+	"github.com/momokii/go-wa-notifier/internal/contacts"
+	"github.com/momokii/go-wa-notifier/internal/jobs"
 	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/internal/notifier"
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/internal/subscriptions"
+	"github.com/momokii/go-wa-notifier/pkg/feeds"
+	"github.com/momokii/go-wa-notifier/pkg/news"
 	"github.com/momokii/go-wa-notifier/pkg/newsapi"
-	"github.com/momokii/go-wa-notifier/pkg/openweatherapi"
 	"github.com/momokii/go-wa-notifier/pkg/utils"
 	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+	"go.mau.fi/whatsmeow/types"
 )
 
 // ================ WHATSAPP HANDLER APPENDIX FUNCTION/DATA TYPE/CONST
 
+// for swagger docs
+type WAPairCodeResponse struct {
+	Error   bool                    `json:"error" example:"false"`
+	Message string                  `json:"message"`
+	Data    models.WhatsappPairCode `json:"data"`
+}
+
 // for swagger docs
 type WAStatusResponse struct {
 	Error   bool   `json:"error" example:"false"`
@@ -28,65 +44,207 @@ type WAStatusResponse struct {
 	} `json:"data"`
 }
 
-func whatsappSendMessages(messages string, numbers []string) error {
-	// setup whatsapp client and not disconnect it when done
-	waClient, err := whatsapp.NewWhatsApp()
-	if err != nil {
-		return fmt.Errorf("failed to initiate WhatsApp: %w", err)
+// for swagger docs
+type WASendResponse struct {
+	Error   bool                        `json:"error" example:"false"`
+	Message string                      `json:"message"`
+	Data    []models.WhatsappSendResult `json:"data"`
+}
+
+// for swagger docs
+type WAGroupsResponse struct {
+	Error   bool                   `json:"error" example:"false"`
+	Message string                 `json:"message"`
+	Data    []models.WhatsappGroup `json:"data"`
+}
+
+// for swagger docs
+type WAGroupResponse struct {
+	Error   bool                 `json:"error" example:"false"`
+	Message string               `json:"message"`
+	Data    models.WhatsappGroup `json:"data"`
+}
+
+func groupFromInfo(info *types.GroupInfo) models.WhatsappGroup {
+	return models.WhatsappGroup{
+		JID:          info.JID.String(),
+		Name:         info.Name,
+		Topic:        info.Topic,
+		Participants: len(info.Participants),
+		IsAnnounce:   info.IsAnnounce,
 	}
+}
+
+// for swagger docs
+type JobResponse struct {
+	Error   bool     `json:"error" example:"false"`
+	Message string   `json:"message"`
+	Data    jobs.Job `json:"data"`
+}
 
-	if !waClient.IsConnected() {
-		return fmt.Errorf("WhatsApp client is not connected")
+// responseSessionError answers 404 for an unknown session and 500 when the session client can not start
+func responseSessionError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, whatsapp.ErrSessionNotFound) {
+		return utils.ResponseError(c, fiber.StatusNotFound, err.Error())
 	}
 
-	// send messages to all numbers
-	for _, number := range numbers {
-		if err := waClient.SendMessage(number, messages, false); err != nil {
-			log.Println("Error sending message on number " + number + " error: " + err.Error())
-			continue
+	return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed Initiate Whatsapp: "+err.Error())
+}
+
+// responseBuildError maps an error from building a broadcast to a 400 when the request data is wrong
+// and to a 500 when one of the upstream apis failed
+func responseBuildError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, notifier.ErrInvalidRequest) {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.ResponseError(c, fiber.StatusInternalServerError, err.Error())
+}
+
+// for swagger docs
+type WACheckNumbersResponse struct {
+	Error   bool                         `json:"error" example:"false"`
+	Message string                       `json:"message"`
+	Data    []models.WhatsappNumberCheck `json:"data"`
+}
+
+// responseContactsError answers 400 when contact_ids has an unknown id and 500 when the contacts can not be read
+func responseContactsError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, contacts.ErrNotFound) {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid contact_ids, "+err.Error())
+	}
+
+	return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get contacts: "+err.Error())
+}
+
+// apiOrigin tells the history that the messages come from this api request, read it before the handler returns
+// because the request is gone by the time an async job sends
+func apiOrigin(c *fiber.Ctx, broadcast string, using_llm, typing bool) outbox.Origin {
+	return outbox.Origin{
+		Broadcast: broadcast,
+		Source:    outbox.SourceAPI,
+		SourceRef: strings.Clone(c.GetRespHeader(fiber.HeaderXRequestID)),
+		UsingLLM:  using_llm,
+		Typing:    typing,
+	}
+}
+
+// rejectRecipients answers 400 with the reasons when the request has invalid numbers and does not allow to skip them,
+// or when no valid number is left. It returns false when the send can go on
+func rejectRecipients(c *fiber.Ctx, numbers []string, invalid []models.WhatsappSendResult, skip_invalid bool) (bool, error) {
+	switch {
+	case len(numbers) == 0:
+		return true, utils.ResponseErrorWithData(c, fiber.StatusBadRequest, "No valid whatsapp number to send to", invalid)
+	case len(invalid) > 0 && !skip_invalid:
+		return true, utils.ResponseErrorWithData(c, fiber.StatusBadRequest, "Some whatsapp numbers are invalid, fix them or set skip_invalid to true to skip them", invalid)
+	}
+
+	return false, nil
+}
+
+// responseSendResults writes the delivery report with a status code based on the outcome,
+// 200 when every number is sent, 207 when only some are sent or some were skipped as invalid,
+// 202 when none is sent yet but the outbox is still retrying or the rate limiter deferred them and 500 when all of them failed
+func responseSendResults(c *fiber.Ctx, message string, results []models.WhatsappSendResult) error {
+	sent, failed, deferred := 0, 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case models.WhatsappSendStatusSent:
+			sent++
+		case models.WhatsappSendStatusFailed, models.WhatsappSendStatusInvalid:
+			failed++
+		case models.WhatsappSendStatusDeferred:
+			deferred++
 		}
-		log.Println("Message sent successfully")
 	}
 
-	return nil
+	switch {
+	case sent == len(results):
+		return utils.ResponseWitData(c, fiber.StatusOK, message, results)
+	case sent > 0 && deferred > 0:
+		return utils.ResponseWitData(c, fiber.StatusMultiStatus, fmt.Sprintf("%s, %d of %d messages not sent yet, %d deferred by the rate limiter", message, len(results)-sent, len(results), deferred), results)
+	case sent > 0:
+		return utils.ResponseWitData(c, fiber.StatusMultiStatus, fmt.Sprintf("%s, %d of %d messages not sent yet", message, len(results)-sent, len(results)), results)
+	case deferred > 0 && failed < len(results):
+		return utils.ResponseWitData(c, fiber.StatusAccepted, fmt.Sprintf("Messages queued, %d deferred by the rate limiter and sent when allowed", deferred), results)
+	case failed < len(results):
+		return utils.ResponseWitData(c, fiber.StatusAccepted, "Messages queued, the outbox will keep retrying them", results)
+	default:
+		return utils.ResponseErrorWithData(c, fiber.StatusInternalServerError, "Failed to send messages to all numbers", results)
+	}
 }
 
 // ================ MAIN HANDLER
 
 type whatsappHandler struct {
-	newsapi_api_key     string
-	openweather_api_key string
-	openaiClient        openai.OpenAI
+	notifier      *notifier.Notifier
+	jobs          *jobs.Manager
+	sessions      *whatsapp.Manager
+	subscriptions *subscriptions.Store
+	contacts      *contacts.Store
 }
 
 func NewWhatsappHandler(
-	newsapi_api_key string,
-	openweather_api_key string,
-	openaiClient openai.OpenAI,
+	notifierService *notifier.Notifier,
+	jobManager *jobs.Manager,
+	sessions *whatsapp.Manager,
+	subscriptionStore *subscriptions.Store,
+	contactStore *contacts.Store,
 ) (*whatsappHandler, error) {
 
-	if newsapi_api_key == "" {
-		return nil, fmt.Errorf("newsapi_api_key is required")
+	if notifierService == nil {
+		return nil, fmt.Errorf("notifier is required")
+	}
+
+	if jobManager == nil {
+		return nil, fmt.Errorf("job manager is required")
+	}
+
+	if sessions == nil {
+		return nil, fmt.Errorf("whatsapp sessions are required")
+	}
+
+	if subscriptionStore == nil {
+		return nil, fmt.Errorf("subscription store is required")
+	}
+
+	if contactStore == nil {
+		return nil, fmt.Errorf("contact store is required")
 	}
 
 	return &whatsappHandler{
-		newsapi_api_key:     newsapi_api_key,
-		openweather_api_key: openweather_api_key,
-		openaiClient:        openaiClient,
+		notifier:      notifierService,
+		jobs:          jobManager,
+		sessions:      sessions,
+		subscriptions: subscriptionStore,
+		contacts:      contactStore,
 	}, nil
 }
 
+// submitJob runs the broadcast in the background and answers 202 with the job to poll
+func (h *whatsappHandler) submitJob(c *fiber.Ctx, job_type string, run jobs.RunFunc) error {
+	job := h.jobs.Submit(job_type, run)
+
+	return utils.ResponseWitData(c, fiber.StatusAccepted, "Job accepted, check the progress at /api/jobs/"+job.ID, job)
+}
+
 // SendMessages godoc
 //
 //	@Summary		Send messages custom to whatsapp
-//	@Description	Send messages custom to whatsapp
+//	@Description	Send messages custom to whatsapp, with async set to true it answers 202 with a job id to poll at /jobs/{id}
 //	@Tags			News
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		models.WhatsappMessagesReq	true	"body request detail"
-//	@Success		200		{object}	utils.MessageResponseSuccess
+//	@Success		200		{object}	handlers.WASendResponse
+//	@Success		202		{object}	handlers.WASendResponse
+//	@Success		207		{object}	handlers.WASendResponse
 //	@Failure		400		{object}	utils.MessageResponseError
-//	@Failure		500		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	handlers.WASendResponse
 //	@Router			/wa/messages [post]
 func (h *whatsappHandler) SendMessages(c *fiber.Ctx) error {
 
@@ -100,34 +258,73 @@ func (h *whatsappHandler) SendMessages(c *fiber.Ctx) error {
 		return utils.ResponseError(c, fiber.StatusBadRequest, "Messages is required")
 	}
 
-	if len(req_body.WhatsappNumbers) == 0 {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers is required")
+	if len(req_body.WhatsappNumbers) == 0 && len(req_body.Tags) == 0 && len(req_body.ContactIDs) == 0 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers, tags or contact_ids is required")
+	}
+
+	contact_list, err := h.contacts.Resolve(c.UserContext(), req_body.ContactIDs, req_body.Tags)
+	if err != nil {
+		return responseContactsError(c, err)
+	}
+
+	recipients := append(req_body.WhatsappNumbers, contacts.Numbers(contact_list)...)
+	if len(recipients) == 0 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "No contact has one of the tags")
 	}
 
 	// check that max numbers send is 100
-	if len(req_body.WhatsappNumbers) > 100 {
+	if len(recipients) > 100 {
 		return utils.ResponseError(c, fiber.StatusBadRequest, "Max Whatsapp numbers is 100")
 	}
 
+	if _, err := h.sessions.Get(req_body.Session); err != nil {
+		return responseSessionError(c, err)
+	}
+
+	numbers, invalid := h.notifier.CheckRecipients(req_body.Session, recipients)
+	if rejected, err := rejectRecipients(c, numbers, invalid, req_body.SkipInvalid); rejected {
+		return err
+	}
+
 	// send messages to all numbers
-	if err := whatsappSendMessages(req_body.Messages, req_body.WhatsappNumbers); err != nil {
+	origin := apiOrigin(c, outbox.BroadcastCustom, false, req_body.Typing)
+	if req_body.Async {
+		return h.submitJob(c, "messages", func(ctx context.Context, progress func(stage string)) ([]models.WhatsappSendResult, error) {
+			progress(notifier.StageSending)
+			results, err := h.notifier.Send(ctx, req_body.Session, origin, req_body.Messages, numbers)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(results, invalid...), nil
+		})
+	}
+
+	results, err := h.notifier.Send(c.UserContext(), req_body.Session, origin, req_body.Messages, numbers)
+	if err != nil {
 		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to send messages: "+err.Error())
 	}
 
-	return utils.ResponseMessage(c, fiber.StatusOK, "Send Messages to Whatsapp")
+	return responseSendResults(c, "Send Messages to Whatsapp", append(results, invalid...))
 }
 
 // SendNewsAPIWhatsapp godoc
 //
 //	@Summary		Send news to whatsapp
-//	@Description	Send news to whatsapp
+//	@Description	Send the top headlines of a category, country or sources to whatsapp. They come from NewsAPI, or from the fallback feeds of the category while NewsAPI is rate limited or down. With rich set to true every headline is sent as an image message with one result per number and message. With async set to true it answers 202 with a job id to poll at /jobs/{id}
 //	@Tags			News
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		models.NewsSendWhatsappReq	true	"body request detail"
-//	@Success		200		{object}	utils.MessageResponseSuccess
+//	@Success		200		{object}	handlers.WASendResponse
+//	@Success		202		{object}	handlers.WASendResponse
+//	@Success		207		{object}	handlers.WASendResponse
 //	@Failure		400		{object}	utils.MessageResponseError
-//	@Failure		500		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	handlers.WASendResponse
 //	@Router			/wa/news [post]
 func (h *whatsappHandler) SendNewsAPIWhatsapp(c *fiber.Ctx) error {
 
@@ -136,120 +333,314 @@ func (h *whatsappHandler) SendNewsAPIWhatsapp(c *fiber.Ctx) error {
 		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if len(req_body.WhatsappNumbers) == 0 {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers is required")
+	if len(req_body.WhatsappNumbers) == 0 && len(req_body.Tags) == 0 && len(req_body.ContactIDs) == 0 && !req_body.Subscribers {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers, tags or contact_ids is required, or set subscribers to true")
+	}
+
+	if req_body.Category == "" && req_body.Country == "" && req_body.Sources == "" {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Category, country or sources is required")
 	}
 
-	if req_body.Category == "" {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "Category is required")
+	// subscriptions are made per category
+	if req_body.Subscribers && req_body.Category == "" {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Category is required to send to the subscribers")
 	}
 
-	// create req struct for newsapi
-	// this will be adjust to my need for whastapp notifier that one req will be max get 10 top headlines for better expererience
-	query_newsapi := newsapi.NewsAPITopHeadlinesReq{
-		PageSize: 10,
-		Page:     1,
+	query := news.Query{
 		Category: req_body.Category,
+		Country:  req_body.Country,
+		Sources:  req_body.Sources,
 	}
 
-	// call newsapi to get the news
-	news_resp, err := newsapi.NewsAPITopHeadlines(h.newsapi_api_key, query_newsapi)
+	// check it here too so an async job with a bad category, country or sources is rejected before it is accepted
+	if err := query.Validate(); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid news: "+err.Error())
+	}
+
+	if req_body.UsingLLM && req_body.Category != "" {
+		if _, err := utils.GetNewsType(req_body.Category); err != nil {
+			return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid category: "+err.Error())
+		}
+	}
+
+	session := whatsapp.SessionName(req_body.Session)
+	if _, err := h.sessions.Get(session); err != nil {
+		return responseSessionError(c, err)
+	}
+
+	contact_list, err := h.contacts.Resolve(c.UserContext(), req_body.ContactIDs, req_body.Tags)
 	if err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Error Get News Data: "+err.Error())
+		return responseContactsError(c, err)
+	}
+
+	recipients := append(req_body.WhatsappNumbers, contacts.Numbers(contact_list)...)
+	if len(recipients) == 0 && !req_body.Subscribers {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "No contact has one of the tags")
 	}
 
-	// check if newsapi response is error from the api or not
-	if news_resp.Status != "ok" {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get news from newsapi: "+news_resp.Message)
+	// check that max numbers send is 100
+	if len(recipients) > 100 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Max Whatsapp numbers is 100")
 	}
 
-	// api call success, process the articles
-	category_uppercase := strings.ToUpper(req_body.Category)
-	message_whatsapp := fmt.Sprintf("📰 *TOP %s NEWS TODAY* 📰\n\n", category_uppercase)
+	numbers, invalid := []string{}, []models.WhatsappSendResult{}
+	if len(recipients) > 0 {
+		numbers, invalid = h.notifier.CheckRecipients(session, recipients)
+		if rejected, err := rejectRecipients(c, numbers, invalid, req_body.SkipInvalid); rejected {
+			return err
+		}
+	}
 
-	var news_data string
-	for i, article := range news_resp.Articles {
-		message_whatsapp += fmt.Sprintf("*%d. %s*\n", i+1, article.Title)
-		message_whatsapp += fmt.Sprintf("📄 *Source:* %s\n", article.Source.Name)
+	if req_body.Subscribers {
+		subscribers, err := h.subscriptions.NewsNumbers(c.UserContext(), session, req_body.Category)
+		if err != nil {
+			return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get subscribers: "+err.Error())
+		}
 
-		if article.Author != "" {
-			message_whatsapp += fmt.Sprintf("✍️ *Author:* %s\n", article.Author)
+		numbers = subscriptions.MergeNumbers(numbers, subscribers)
+		if len(numbers) == 0 {
+			return utils.ResponseError(c, fiber.StatusBadRequest, "No one is subscribed to the "+req_body.Category+" news yet")
 		}
+	}
 
-		if article.PublishedAt != "" {
-			// Parse the ISO 8601 date format
-			// example of article.PublishedAt: "2025-04-04T14:19:00Z"
-			t, err := time.Parse(time.RFC3339, article.PublishedAt)
-			if err == nil {
-				// Format as a more readable date: e.g., "04 Apr 2025, 14:19"
-				formattedDate := t.Format("02 Jan 2006, 15:04")
-				message_whatsapp += fmt.Sprintf("📅 *Published:* %s\n", formattedDate)
-			} else {
-				// Fallback to original format if parsing fails
-				message_whatsapp += fmt.Sprintf("📅 *Published:* %s\n", article.PublishedAt)
-			}
+	// build the news message and send it to all numbers
+	origin := apiOrigin(c, outbox.BroadcastNews, req_body.UsingLLM, req_body.Typing)
+	send_news := func(ctx context.Context, progress func(stage string)) ([]models.WhatsappSendResult, error) {
+		payloads, err := h.notifier.BuildNewsPayloads(ctx, query, req_body.UsingLLM, req_body.Rich, progress)
+		if err != nil {
+			return nil, err
 		}
 
-		if article.Description != "" {
-			message_whatsapp += fmt.Sprintf("📝 *Summary:* %s\n", article.Description)
+		progress(notifier.StageSending)
+		results, err := h.notifier.SendPayloads(ctx, session, origin, payloads, numbers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send messages: %w", err)
 		}
 
-		message_whatsapp += fmt.Sprintf("🔗 *Read more:* %s\n\n", article.Url)
+		return append(results, invalid...), nil
+	}
+
+	if req_body.Async {
+		return h.submitJob(c, "news", send_news)
+	}
+
+	results, err := send_news(c.UserContext(), func(string) {})
+	if err != nil {
+		return responseBuildError(c, err)
+	}
+
+	return responseSendResults(c, "News sent to WhatsApp", results)
+}
+
+// SendNewsSearchWhatsapp godoc
+//
+//	@Summary		Send news search to whatsapp
+//	@Description	Search every NewsAPI article for the keywords, like a company name or its competitors, and send the newest matches with the same formatting, rich and llm options as /wa/news. With async set to true it answers 202 with a job id to poll at /jobs/{id}
+//	@Tags			News
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.NewsSearchSendWhatsappReq	true	"body request detail"
+//	@Success		200		{object}	handlers.WASendResponse
+//	@Success		202		{object}	handlers.WASendResponse
+//	@Success		207		{object}	handlers.WASendResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	handlers.WASendResponse
+//	@Router			/wa/news/search [post]
+func (h *whatsappHandler) SendNewsSearchWhatsapp(c *fiber.Ctx) error {
+
+	req_body := new(models.NewsSearchSendWhatsappReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if len(req_body.WhatsappNumbers) == 0 && len(req_body.Tags) == 0 && len(req_body.ContactIDs) == 0 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers, tags or contact_ids is required")
+	}
+
+	query := newsapi.NewsAPIEverythingReq{
+		Q:              req_body.Query,
+		Searchin:       req_body.SearchIn,
+		PageSize:       req_body.Limit,
+		SortBy:         req_body.SortBy,
+		From:           req_body.From,
+		To:             req_body.To,
+		Domains:        req_body.Domains,
+		ExcludeDomains: req_body.ExcludeDomains,
+		Language:       req_body.Language,
+		Sources:        req_body.Sources,
+	}
+
+	// check it here too so an async job with a bad search is rejected before it is accepted
+	if err := query.Validate(); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid search: "+err.Error())
+	}
+
+	session := whatsapp.SessionName(req_body.Session)
+	if _, err := h.sessions.Get(session); err != nil {
+		return responseSessionError(c, err)
+	}
+
+	contact_list, err := h.contacts.Resolve(c.UserContext(), req_body.ContactIDs, req_body.Tags)
+	if err != nil {
+		return responseContactsError(c, err)
+	}
+
+	recipients := append(req_body.WhatsappNumbers, contacts.Numbers(contact_list)...)
+	if len(recipients) == 0 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "No contact has one of the tags")
+	}
+
+	// check that max numbers send is 100
+	if len(recipients) > 100 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Max Whatsapp numbers is 100")
 	}
 
-	// continue using llm if using_llm is true
-	if req_body.UsingLLM {
-		news_data = message_whatsapp
+	numbers, invalid := h.notifier.CheckRecipients(session, recipients)
+	if rejected, err := rejectRecipients(c, numbers, invalid, req_body.SkipInvalid); rejected {
+		return err
+	}
 
-		news_type, err := utils.GetNewsType(req_body.Category)
+	// search the news and send the matches to all numbers
+	origin := apiOrigin(c, outbox.BroadcastNews, req_body.UsingLLM, req_body.Typing)
+	send_news := func(ctx context.Context, progress func(stage string)) ([]models.WhatsappSendResult, error) {
+		payloads, err := h.notifier.BuildNewsSearchPayloads(ctx, query, req_body.UsingLLM, req_body.Rich, progress)
 		if err != nil {
-			return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid category: "+err.Error())
+			return nil, err
 		}
 
-		prompt_news_summaries, err := utils.GenerateNewsSummariesPrompt(news_data, news_type)
+		progress(notifier.StageSending)
+		results, err := h.notifier.SendPayloads(ctx, session, origin, payloads, numbers)
 		if err != nil {
-			return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to generate news summaries: "+err.Error())
+			return nil, fmt.Errorf("failed to send messages: %w", err)
 		}
 
-		// create message and send to openai for summarization
-		message_summaries := []openai.OAMessageReq{
-			{
-				Role:    "user",
-				Content: prompt_news_summaries,
-			},
+		return append(results, invalid...), nil
+	}
+
+	if req_body.Async {
+		return h.submitJob(c, "news_search", send_news)
+	}
+
+	results, err := send_news(c.UserContext(), func(string) {})
+	if err != nil {
+		return responseBuildError(c, err)
+	}
+
+	return responseSendResults(c, "News search sent to WhatsApp", results)
+}
+
+// SendFeedWhatsapp godoc
+//
+//	@Summary		Send feed to whatsapp
+//	@Description	Read an RSS 2.0 or Atom feed, for outlets that are not in NewsAPI, and send its latest items with the same formatting, rich and llm options as /wa/news. With async set to true it answers 202 with a job id to poll at /jobs/{id}
+//	@Tags			News
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.FeedSendWhatsappReq	true	"body request detail"
+//	@Success		200		{object}	handlers.WASendResponse
+//	@Success		202		{object}	handlers.WASendResponse
+//	@Success		207		{object}	handlers.WASendResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	handlers.WASendResponse
+//	@Router			/wa/feeds [post]
+func (h *whatsappHandler) SendFeedWhatsapp(c *fiber.Ctx) error {
+
+	req_body := new(models.FeedSendWhatsappReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if len(req_body.WhatsappNumbers) == 0 && len(req_body.Tags) == 0 && len(req_body.ContactIDs) == 0 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers, tags or contact_ids is required")
+	}
+
+	if req_body.FeedURL == "" {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Feed url is required")
+	}
+
+	if err := feeds.ValidateURL(req_body.FeedURL); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	session := whatsapp.SessionName(req_body.Session)
+	if _, err := h.sessions.Get(session); err != nil {
+		return responseSessionError(c, err)
+	}
+
+	contact_list, err := h.contacts.Resolve(c.UserContext(), req_body.ContactIDs, req_body.Tags)
+	if err != nil {
+		return responseContactsError(c, err)
+	}
+
+	recipients := append(req_body.WhatsappNumbers, contacts.Numbers(contact_list)...)
+	if len(recipients) == 0 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "No contact has one of the tags")
+	}
+
+	// check that max numbers send is 100
+	if len(recipients) > 100 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Max Whatsapp numbers is 100")
+	}
+
+	numbers, invalid := h.notifier.CheckRecipients(session, recipients)
+	if rejected, err := rejectRecipients(c, numbers, invalid, req_body.SkipInvalid); rejected {
+		return err
+	}
+
+	// read the feed and send its latest items to all numbers
+	origin := apiOrigin(c, outbox.BroadcastNews, req_body.UsingLLM, req_body.Typing)
+	send_feed := func(ctx context.Context, progress func(stage string)) ([]models.WhatsappSendResult, error) {
+		payloads, err := h.notifier.BuildFeedPayloads(ctx, req_body.FeedURL, req_body.Limit, req_body.UsingLLM, req_body.Rich, progress)
+		if err != nil {
+			return nil, err
 		}
 
-		summaries_news_resp, err := h.openaiClient.OpenAIGetFirstContentDataResp(&message_summaries, false, nil, false, nil)
+		progress(notifier.StageSending)
+		results, err := h.notifier.SendPayloads(ctx, session, origin, payloads, numbers)
 		if err != nil {
-			return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get summaries from OpenAI: "+err.Error())
+			return nil, fmt.Errorf("failed to send messages: %w", err)
 		}
 
-		// add the summaries to the message
-		message_whatsapp += fmt.Sprintf("🤖 *AI Summaries:*\n%s\n\n", summaries_news_resp.Content)
+		return append(results, invalid...), nil
 	}
 
-	// add footer to the message
-	message_whatsapp += "Powered by NewsAPI | Kelana Chandra Helyandika | kelanach.xyz"
+	if req_body.Async {
+		return h.submitJob(c, "feed", send_feed)
+	}
 
-	// send messages to all numbers
-	if err := whatsappSendMessages(message_whatsapp, req_body.WhatsappNumbers); err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to send messages: "+err.Error())
+	results, err := send_feed(c.UserContext(), func(string) {})
+	if err != nil {
+		return responseBuildError(c, err)
 	}
 
-	return utils.ResponseMessage(c, fiber.StatusOK, "News sent to WhatsApp successfully")
+	return responseSendResults(c, "Feed sent to WhatsApp", results)
 }
 
 // SendWeatherAPIWhatsapp godoc
 //
 //	@Summary		Send weather daily forecast to whatsapp
-//	@Description	Send weather daily forecast
+//	@Description	Send weather daily forecast, with async set to true it answers 202 with a job id to poll at /jobs/{id}
 //	@Tags			News
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		models.WeatherSendWhatsappReq	true	"body request detail"
-//	@Success		200		{object}	utils.MessageResponseSuccess
+//	@Success		200		{object}	handlers.WASendResponse
+//	@Success		202		{object}	handlers.WASendResponse
+//	@Success		207		{object}	handlers.WASendResponse
 //	@Failure		400		{object}	utils.MessageResponseError
-//	@Failure		500		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	handlers.WASendResponse
 //	@Router			/wa/weathers [post]
 func (h *whatsappHandler) SendWeatherAPIWhatsapp(c *fiber.Ctx) error {
 
@@ -259,11 +650,11 @@ func (h *whatsappHandler) SendWeatherAPIWhatsapp(c *fiber.Ctx) error {
 		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	if len(req_body.WhatsappNumbers) == 0 {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers is required")
+	if len(req_body.WhatsappNumbers) == 0 && len(req_body.Tags) == 0 && len(req_body.ContactIDs) == 0 && !req_body.Subscribers {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers, tags or contact_ids is required, or set subscribers to true")
 	}
 
-	if req_body.Type == "" && req_body.Type != "today" && req_body.Type != "tomorrow" {
+	if req_body.Type != "today" && req_body.Type != "tomorrow" {
 		return utils.ResponseError(c, fiber.StatusBadRequest, "Type is required and must be 'today' or 'tomorrow'")
 	}
 
@@ -275,125 +666,390 @@ func (h *whatsappHandler) SendWeatherAPIWhatsapp(c *fiber.Ctx) error {
 		return utils.ResponseError(c, fiber.StatusBadRequest, "Longitude must be between -180 and 180")
 	}
 
-	// process the weather data here
+	session := whatsapp.SessionName(req_body.Session)
+	if _, err := h.sessions.Get(session); err != nil {
+		return responseSessionError(c, err)
+	}
 
-	// get date, based on today or tomorrow
-	var date, reportType, localTime string
-	if req_body.Type == "today" {
-		date = time.Now().Format("2006-01-02")
-		localTime = time.Now().Format("15:04:05")
-		reportType = "today"
-	} else {
-		date = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
-		localTime = time.Now().AddDate(0, 0, 1).Format("15:04:05")
-		reportType = "tomorrow"
+	contact_list, err := h.contacts.Resolve(c.UserContext(), req_body.ContactIDs, req_body.Tags)
+	if err != nil {
+		return responseContactsError(c, err)
 	}
 
-	weather_base_req := openweatherapi.OpenWeatherAPIV3OneCallBaseReq{
-		Lat:   req_body.Lat,
-		Lon:   req_body.Lon,
-		AppID: h.openweather_api_key,
-		Units: "metric", // using celcius as default for this endpoint
+	recipients := append(req_body.WhatsappNumbers, contacts.Numbers(contact_list)...)
+	if len(recipients) == 0 && !req_body.Subscribers {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "No contact has one of the tags")
 	}
 
-	// first, get OVERVIEW DATA
-	weather_overview_req := openweatherapi.OpenWeatherAPIV3OneCallOverviewReq{
-		Date:                           date,
-		OpenWeatherAPIV3OneCallBaseReq: weather_base_req,
+	// check that max numbers send is 100
+	if len(recipients) > 100 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Max Whatsapp numbers is 100")
 	}
 
-	weather_overview, err := openweatherapi.OpenWeatherV3OneCallOverviewAPI(weather_overview_req)
-	if err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get weather overview: "+err.Error())
+	// contacts with a default location get the weather there, every other number the weather of the requested location
+	targets := []notifier.WeatherTarget{}
+	numbers, invalid := []string{}, []models.WhatsappSendResult{}
+	if len(recipients) > 0 {
+		numbers, invalid = h.notifier.CheckRecipients(session, recipients)
+		if rejected, err := rejectRecipients(c, numbers, invalid, req_body.SkipInvalid); rejected {
+			return err
+		}
+
+		targets = notifier.ContactTargets(req_body.Lat, req_body.Lon, numbers, contact_list)
 	}
 
-	// second, get DAILY AGGREATE DATA
-	weather_daily_req := openweatherapi.OpenWeatherAPIV3OneCallDailySummaryReq{
-		Date:                           date,
-		OpenWeatherAPIV3OneCallBaseReq: weather_base_req,
+	// subscribers get the weather of their own shared location
+	if req_body.Subscribers {
+		groups, err := h.subscriptions.WeatherGroups(c.UserContext(), session)
+		if err != nil {
+			return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get subscribers: "+err.Error())
+		}
+
+		targets = append(targets, notifier.SubscriberTargets(groups, numbers)...)
+		if len(targets) == 0 {
+			return utils.ResponseError(c, fiber.StatusBadRequest, "No one with a shared location is subscribed to the weather yet")
+		}
 	}
 
-	weather_daily_aggregate, err := openweatherapi.OpenWeatherV3OneCallDailySummaryAPI(weather_daily_req)
-	if err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get weather daily aggregate: "+err.Error())
+	// build the weather message of every location and send it to its numbers
+	origin := apiOrigin(c, outbox.BroadcastWeather, req_body.UsingLLM, req_body.Typing)
+	send_weather := func(ctx context.Context, progress func(stage string)) ([]models.WhatsappSendResult, error) {
+		results, err := h.notifier.SendWeather(ctx, session, origin, req_body.Type, targets, progress)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(results, invalid...), nil
 	}
 
-	// third, get the weather data for hourly to get 24 hours data with onecall basic api
-	weather_onecall_24hr := openweatherapi.OpenWeatherAPIV3OneCallReq{
-		OpenWeatherAPIV3OneCallBaseReq: weather_base_req,
-		Exclude:                        []string{"current", "minutely", "daily", "alerts"}, // just get hourly data
+	if req_body.Async {
+		return h.submitJob(c, "weather", send_weather)
 	}
 
-	weather_onecall_24hr_resp, err := openweatherapi.OpenWeatherV3OneCallAPI(weather_onecall_24hr)
+	results, err := send_weather(c.UserContext(), func(string) {})
 	if err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get weather onecall 24 hours: "+err.Error())
-	}
-
-	// get 24 data hourly from the response
-	weather_24hr := weather_onecall_24hr_resp.Hourly[0:24]
-
-	weatherData := openweatherapi.WeatherDataAggregate{
-		Date:             date,
-		ReportType:       reportType,
-		Latitude:         req_body.Lat,
-		Longitude:        req_body.Lon,
-		WeatherOverview:  weather_overview.WeatherOverview,
-		Timezone:         weather_overview.TZ,
-		DailyAggregate:   weather_daily_aggregate,
-		HourlyForecast:   weather_24hr,
-		CurrentTimeLocal: localTime,
-	}
-
-	var messages_wa string
-	// check if using llm or not, if not just send the weather data to whatsapp
-	if req_body.UsingLLM {
-		// generate prompt
-		prompt := utils.GenerateWeatherPrompt(&weatherData)
-
-		// send to openai for summarization
-		messages := []openai.OAMessageReq{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
+		return responseBuildError(c, err)
+	}
+
+	return responseSendResults(c, "Send WeatherAPI to Whatsapp", results)
+}
+
+// SendMedia godoc
+//
+//	@Summary		Send media to whatsapp
+//	@Description	Send an image, document, audio or video to whatsapp, from an uploaded file or fetched from a url. With async set to true it answers 202 with a job id to poll at /jobs/{id}
+//	@Tags			News
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			whatsapp_numbers	formData	string	true	"comma separated numbers with the country code, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us"
+//	@Param			file				formData	file	false	"media file, required when url is empty"
+//	@Param			url					formData	string	false	"url to fetch the media from, required when file is empty"
+//	@Param			type				formData	string	false	"media type, guessed from the mimetype when empty"	Enums(image, document, audio, video)
+//	@Param			caption				formData	string	false	"caption of the media, not shown for audio"
+//	@Param			session				formData	string	false	"whatsapp session to send from, the default session when empty"
+//	@Param			typing				formData	bool	false	"show recording or typing in the chat before the media is sent"
+//	@Param			skip_invalid		formData	bool	false	"skip numbers that are invalid or not on WhatsApp instead of rejecting the request"
+//	@Param			async				formData	bool	false	"run in the background and answer with a job id"
+//	@Success		200					{object}	handlers.WASendResponse
+//	@Success		202					{object}	handlers.WASendResponse
+//	@Success		207					{object}	handlers.WASendResponse
+//	@Failure		400					{object}	utils.MessageResponseError
+//	@Failure		401					{object}	utils.MessageResponseError
+//	@Failure		403					{object}	utils.MessageResponseError
+//	@Failure		404					{object}	utils.MessageResponseError
+//	@Failure		500					{object}	handlers.WASendResponse
+//	@Router			/wa/media [post]
+func (h *whatsappHandler) SendMedia(c *fiber.Ctx) error {
+
+	whatsapp_numbers := []string{}
+	for _, number := range strings.Split(c.FormValue("whatsapp_numbers"), ",") {
+		if number = strings.TrimSpace(number); number != "" {
+			whatsapp_numbers = append(whatsapp_numbers, number)
+		}
+	}
+
+	if len(whatsapp_numbers) == 0 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers is required")
+	}
+
+	if len(whatsapp_numbers) > 100 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Max Whatsapp numbers is 100")
+	}
+
+	session := c.FormValue("session")
+	if _, err := h.sessions.Get(session); err != nil {
+		return responseSessionError(c, err)
+	}
+
+	numbers, invalid := h.notifier.CheckRecipients(session, whatsapp_numbers)
+	if rejected, err := rejectRecipients(c, numbers, invalid, c.FormValue("skip_invalid") == "true"); rejected {
+		return err
+	}
+
+	// get the media from the uploaded file or from the url
+	var data []byte
+	var mimetype, file_name string
+	if file, err := c.FormFile("file"); err == nil {
+		if file.Size > whatsapp.MaxMediaSize {
+			return utils.ResponseError(c, fiber.StatusBadRequest, fmt.Sprintf("Media is bigger than %d MB", whatsapp.MaxMediaSize>>20))
 		}
 
-		weather_ai, err := h.openaiClient.OpenAIGetFirstContentDataResp(&messages, false, nil, false, nil)
+		opened, err := file.Open()
 		if err != nil {
-			return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to get weather summary: "+err.Error())
+			return utils.ResponseError(c, fiber.StatusBadRequest, "Failed to read file: "+err.Error())
 		}
+		defer opened.Close()
 
-		// format response to message whatsapp
-		messages_wa = utils.FormatWeatherMessage(weather_ai.Content, &weatherData)
+		data, err = io.ReadAll(opened)
+		if err != nil {
+			return utils.ResponseError(c, fiber.StatusBadRequest, "Failed to read file: "+err.Error())
+		}
+
+		file_name = file.Filename
+		mimetype = file.Header.Get("Content-Type")
+		if mimetype == "" || mimetype == "application/octet-stream" {
+			mimetype = http.DetectContentType(data)
+		}
+	} else if media_url := c.FormValue("url"); media_url != "" {
+		data, mimetype, file_name, err = whatsapp.FetchMedia(media_url)
+		if err != nil {
+			return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+		}
 	} else {
-		// If not using LLM, format the weather data manually
-		messages_wa = utils.FormatWeatherMessageManual(&weatherData)
+		return utils.ResponseError(c, fiber.StatusBadRequest, "File or url is required")
 	}
 
-	// send messages
-	if err := whatsappSendMessages(messages_wa, req_body.WhatsappNumbers); err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to send messages: "+err.Error())
+	media_type, err := whatsapp.ParseMediaType(c.FormValue("type"), mimetype)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	payload := outbox.Payload{
+		Type:          string(media_type),
+		Body:          c.FormValue("caption"),
+		Media:         data,
+		MediaMimeType: mimetype,
+		MediaFileName: file_name,
+	}
+
+	origin := apiOrigin(c, outbox.BroadcastMedia, false, c.FormValue("typing") == "true")
+	if c.FormValue("async") == "true" {
+		return h.submitJob(c, "media", func(ctx context.Context, progress func(stage string)) ([]models.WhatsappSendResult, error) {
+			progress(notifier.StageSending)
+			results, err := h.notifier.SendPayloads(ctx, session, origin, []outbox.Payload{payload}, numbers)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(results, invalid...), nil
+		})
+	}
+
+	results, err := h.notifier.SendPayloads(c.UserContext(), session, origin, []outbox.Payload{payload}, numbers)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed to send media: "+err.Error())
+	}
+
+	return responseSendResults(c, "Send Media to Whatsapp", append(results, invalid...))
+}
+
+// WhatsAppPairPhone godoc
+//
+//	@Summary		Pair whatsapp with a phone number
+//	@Description	Link the session without scanning the QR code. WhatsApp notifies the phone of the number, then enter the returned code from Linked devices > Link with phone number instead. The code expires after a few minutes, ask a new one when it does
+//	@Tags			Whatsapp
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.WhatsappPairPhoneReq	true	"body request detail"
+//	@Success		200		{object}	handlers.WAPairCodeResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		409		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/wa/pair [post]
+func (h *whatsappHandler) WhatsAppPairPhone(c *fiber.Ctx) error {
+	req_body := new(models.WhatsappPairPhoneReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if strings.TrimSpace(req_body.PhoneNumber) == "" {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Phone number is required")
+	}
+
+	code, err := h.sessions.PairPhone(req_body.Session, req_body.PhoneNumber)
+	if err != nil {
+		switch {
+		case errors.Is(err, whatsapp.ErrInvalidPhone):
+			return utils.ResponseError(c, fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, whatsapp.ErrAlreadyPaired):
+			return utils.ResponseError(c, fiber.StatusConflict, "Session is already paired, log it out first to pair another phone")
+		case errors.Is(err, whatsapp.ErrSessionNotFound):
+			return responseSessionError(c, err)
+		}
+
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed Pair Whatsapp: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "WhatsApp pairing code", models.WhatsappPairCode{
+		Session: whatsapp.SessionName(req_body.Session),
+		Code:    code,
+	})
+}
+
+// WhatsAppCheckNumbers godoc
+//
+//	@Summary		Check whatsapp numbers
+//	@Description	Normalize the numbers to the international format and check if they are registered on WhatsApp, numbers starting with 0 get the DEFAULT_COUNTRY_CODE. Answers are cached so checking the same numbers again is cheap
+//	@Tags			Whatsapp
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.WhatsappCheckNumbersReq	true	"body request detail"
+//	@Success		200		{object}	handlers.WACheckNumbersResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/wa/check-numbers [post]
+func (h *whatsappHandler) WhatsAppCheckNumbers(c *fiber.Ctx) error {
+	req_body := new(models.WhatsappCheckNumbersReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if len(req_body.WhatsappNumbers) == 0 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Whatsapp numbers is required")
+	}
+
+	if len(req_body.WhatsappNumbers) > 100 {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Max Whatsapp numbers is 100")
+	}
+
+	waClient, err := h.sessions.Get(req_body.Session)
+	if err != nil {
+		return responseSessionError(c, err)
+	}
+
+	checks, err := waClient.CheckNumbers(req_body.WhatsappNumbers)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed Check Numbers: "+err.Error())
+	}
+
+	data := make([]models.WhatsappNumberCheck, 0, len(checks))
+	for _, check := range checks {
+		data = append(data, models.WhatsappNumberCheck{
+			Input:      check.Input,
+			Number:     check.Number,
+			IsGroup:    check.IsGroup,
+			Valid:      check.Valid,
+			OnWhatsApp: check.OnWhatsApp,
+			Reason:     check.Reason,
+		})
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "WhatsApp Numbers Checked", data)
+}
+
+// WhatsAppGroups godoc
+//
+//	@Summary		List joined groups
+//	@Description	List the WhatsApp groups the account is a member of, the jid can be used in whatsapp_numbers of every send endpoint
+//	@Tags			Whatsapp
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			session	query		string	false	"whatsapp session, the default session when empty"
+//	@Success		200		{object}	handlers.WAGroupsResponse
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/wa/groups [get]
+func (h *whatsappHandler) WhatsAppGroups(c *fiber.Ctx) error {
+	waClient, err := h.sessions.Get(c.Query("session"))
+	if err != nil {
+		return responseSessionError(c, err)
+	}
+
+	groups_info, err := waClient.GetJoinedGroups()
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed Get Groups: "+err.Error())
+	}
+
+	groups := make([]models.WhatsappGroup, 0, len(groups_info))
+	for _, info := range groups_info {
+		groups = append(groups, groupFromInfo(info))
 	}
 
-	return utils.ResponseMessage(c, fiber.StatusOK, "Send WeatherAPI to Whatsapp")
+	return utils.ResponseWitData(c, fiber.StatusOK, "WhatsApp Groups", groups)
+}
+
+// WhatsAppJoinGroup godoc
+//
+//	@Summary		Join group from invite link
+//	@Description	Join a WhatsApp group with its invite link, groups that need admin approval are joined once approved
+//	@Tags			Whatsapp
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		models.WhatsappJoinGroupReq	true	"body request detail"
+//	@Success		200		{object}	handlers.WAGroupResponse
+//	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
+//	@Failure		500		{object}	utils.MessageResponseError
+//	@Router			/wa/groups/join [post]
+func (h *whatsappHandler) WhatsAppJoinGroup(c *fiber.Ctx) error {
+	req_body := new(models.WhatsappJoinGroupReq)
+	if err := c.BodyParser(req_body); err != nil {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if req_body.InviteLink == "" {
+		return utils.ResponseError(c, fiber.StatusBadRequest, "Invite link is required")
+	}
+
+	waClient, err := h.sessions.Get(req_body.Session)
+	if err != nil {
+		return responseSessionError(c, err)
+	}
+
+	info, err := waClient.JoinGroupWithLink(req_body.InviteLink)
+	if err != nil {
+		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed Join Group: "+err.Error())
+	}
+
+	return utils.ResponseWitData(c, fiber.StatusOK, "Joined WhatsApp Group", groupFromInfo(info))
 }
 
 // WhatsAppLogout godoc
 //
 //	@Summary		Logout Whatsapp Account
-//	@Description	Logout Whatsapp Account
+//	@Description	Logout Whatsapp Account, the session stays and starts a new QR login
 //	@Tags			Whatsapp
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			session	query		string	false	"whatsapp session, the default session when empty"
 //	@Success		200		{object}	utils.MessageResponseSuccess
 //	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
 //	@Failure		500		{object}	utils.MessageResponseError
 //	@Router			/wa/logout [post]
 func (h *whatsappHandler) WhatsAppLogout(c *fiber.Ctx) error {
 
-	waClient, err := whatsapp.NewWhatsApp()
+	waClient, err := h.sessions.Get(c.Query("session"))
 	if err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed Initiate Whatsapp: "+err.Error())
+		return responseSessionError(c, err)
 	}
 
 	if err := waClient.Logout(); err != nil {
@@ -406,21 +1062,30 @@ func (h *whatsappHandler) WhatsAppLogout(c *fiber.Ctx) error {
 // WAStatus godoc
 //
 //	@Summary		Check Whatsapp Status
-//	@Description	Check Whatsapp Status
+//	@Description	Check Whatsapp Status, the QR code to pair the session is only returned to admin keys and the web dashboard login
 //	@Tags			Whatsapp
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			session	query		string	false	"whatsapp session, the default session when empty"
 //	@Success		200		{object}	handlers.WAStatusResponse
 //	@Failure		400		{object}	utils.MessageResponseError
+//	@Failure		401		{object}	utils.MessageResponseError
+//	@Failure		403		{object}	utils.MessageResponseError
+//	@Failure		404		{object}	utils.MessageResponseError
 //	@Failure		500		{object}	utils.MessageResponseError
 //	@Router			/wa/status [get]
 func (h *whatsappHandler) WAStatus(c *fiber.Ctx) error {
-	waClient, err := whatsapp.NewWhatsApp()
+	waClient, err := h.sessions.Get(c.Query("session"))
 	if err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Failed Initiate Whatsapp: "+err.Error())
+		return responseSessionError(c, err)
 	}
 
+	// whoever scans the QR code takes the session over, so only admins get it
 	qrCode, qrReady := waClient.GetQRCode()
+	if !auth.IsAdmin(c) {
+		qrCode = ""
+	}
 	isConnected := waClient.IsConnected()
 
 	return utils.ResponseWitData(c, fiber.StatusOK, "WhatsApp Status", fiber.Map{
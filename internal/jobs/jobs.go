@@ -0,0 +1,143 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/momokii/go-wa-notifier/internal/models"
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// StageDone is the stage of a job that is no longer running
+const StageDone = "done"
+
+// Job is the state of one broadcast running in the background
+type Job struct {
+	ID         string                      `json:"id" example:"0b6c1f2e-4f43-4d3c-9a55-0c54a5f2a1d7"`
+	Type       string                      `json:"type" example:"news"` // options: news, news_search, feed, weather, messages, media
+	Status     string                      `json:"status" example:"running"`
+	Stage      string                      `json:"stage" example:"sending"` // options: queued, fetching, llm, sending, done
+	Results    []models.WhatsappSendResult `json:"results"`
+	Error      string                      `json:"error,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at"`
+	UpdatedAt  time.Time                   `json:"updated_at"`
+	FinishedAt *time.Time                  `json:"finished_at,omitempty"`
+}
+
+// RunFunc does the work of a job, it calls progress every time it moves to the next stage
+type RunFunc func(ctx context.Context, progress func(stage string)) ([]models.WhatsappSendResult, error)
+
+// Manager keeps the jobs in memory, finished jobs are dropped after the retention time.
+// The messages themselves are durable in the outbox, so losing a job on restart only loses its progress view
+type Manager struct {
+	mu        sync.RWMutex
+	jobs      map[string]*Job
+	retention time.Duration
+}
+
+func NewManager(retention time.Duration) *Manager {
+	if retention <= 0 {
+		retention = 24 * time.Hour
+	}
+
+	return &Manager{
+		jobs:      map[string]*Job{},
+		retention: retention,
+	}
+}
+
+// Submit registers a new job and runs it in its own goroutine, the returned copy is the queued state
+func (m *Manager) Submit(job_type string, run RunFunc) Job {
+	now := time.Now()
+	job := &Job{
+		ID:        uuid.NewString(),
+		Type:      job_type,
+		Status:    StatusQueued,
+		Stage:     StatusQueued,
+		Results:   []models.WhatsappSendResult{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	m.mu.Lock()
+	m.purge(now)
+	m.jobs[job.ID] = job
+	snapshot := *job
+	m.mu.Unlock()
+
+	go m.run(job, run)
+
+	return snapshot
+}
+
+func (m *Manager) run(job *Job, run RunFunc) {
+	m.update(job, func(j *Job) {
+		j.Status = StatusRunning
+	})
+
+	results, err := run(context.Background(), func(stage string) {
+		m.update(job, func(j *Job) {
+			j.Stage = stage
+		})
+	})
+
+	m.update(job, func(j *Job) {
+		now := time.Now()
+		j.Stage = StageDone
+		j.FinishedAt = &now
+		if results != nil {
+			j.Results = results
+		}
+
+		if err != nil {
+			j.Status = StatusFailed
+			j.Error = err.Error()
+			log.Printf("Job %s (%s) failed: %s\n", j.ID, j.Type, err.Error())
+			return
+		}
+
+		j.Status = StatusCompleted
+	})
+}
+
+func (m *Manager) update(job *Job, fn func(j *Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fn(job)
+	job.UpdatedAt = time.Now()
+}
+
+// purge drops finished jobs older than the retention, the caller must hold the lock
+func (m *Manager) purge(now time.Time) {
+	for id, job := range m.jobs {
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > m.retention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// Get returns a copy of the job so the caller can read it while the job keeps running
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+
+	snapshot := *job
+	snapshot.Results = append([]models.WhatsappSendResult(nil), job.Results...)
+
+	return snapshot, true
+}
@@ -0,0 +1,11 @@
+package models
+
+type ContactReq struct {
+	Name     string   `json:"name" example:"Budi Santoso"`     // required, name to recognize the contact
+	Number   string   `json:"number" example:"085727771234"`   // required, number with the country code, a leading 0 gets the DEFAULT_COUNTRY_CODE, or a group JID like 120363025246125486@g.us
+	Tags     []string `json:"tags" example:"team,jakarta"`     // tags to send to a group of contacts at once, stored in lowercase
+	Language string   `json:"language" example:"id"`           // preferred language, ISO 639-1 code like id or en
+	Timezone string   `json:"timezone" example:"Asia/Jakarta"` // IANA timezone of the contact
+	Lat      *float64 `json:"lat" example:"-6.2617"`           // latitude of the default location, the weather broadcasts use it instead of the requested location
+	Lon      *float64 `json:"lon" example:"106.8103"`          // longitude of the default location, required with lat
+}
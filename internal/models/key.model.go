@@ -0,0 +1,6 @@
+package models
+
+type APIKeyReq struct {
+	Name   string   `json:"name" example:"reporting"`     // required, name to recognize the key in the audit log
+	Scopes []string `json:"scopes" example:"send,status"` // required, options: send, status, logout, admin
+}
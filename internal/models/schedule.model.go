@@ -0,0 +1,22 @@
+package models
+
+type ScheduleReq struct {
+	Name            string   `json:"name" example:"Morning tech news"`                          // required, name to recognize the schedule
+	Cron            string   `json:"cron" example:"0 7 * * *"`                                  // required, standard 5 fields cron expression or descriptor like @daily
+	Timezone        string   `json:"timezone" example:"Asia/Jakarta"`                           // IANA timezone the cron expression is evaluated in, default UTC
+	JobType         string   `json:"job_type" example:"news"`                                   // required, options: news, weather, message, feed
+	Category        string   `json:"category" example:"technology"`                             // news, options: business, entertainment, general, health, science, sports, technology. news needs a category, country or sources
+	Country         string   `json:"country" example:"us"`                                      // news, ISO 3166-1 code of the country of the headlines, like us or id
+	Sources         string   `json:"sources" example:"bbc-news,the-verge"`                      // news, comma separated NewsAPI source ids from /news/sources, 20 at most, can not be mixed with country or category
+	FeedURL         string   `json:"feed_url" example:"https://www.theverge.com/rss/index.xml"` // required for feed, url of an RSS 2.0 or Atom feed
+	WeatherType     string   `json:"weather_type" example:"today"`                              // required for weather, options: today, tomorrow
+	Lat             float64  `json:"lat" example:"-6.2617"`                                     // required for weather, latitude of the location
+	Lon             float64  `json:"lon" example:"106.8103"`                                    // required for weather, longitude of the location
+	Messages        string   `json:"messages" example:"Hello, this is a scheduled message"`     // required for message, message to be sent
+	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"`    // list of numbers to send to and start with code number like 62 and not 0 like 08123456789, or group JIDs like 120363025246125486@g.us
+	Session         string   `json:"session" example:"default"`                                 // whatsapp session to send from, default session when empty
+	Subscribers     bool     `json:"subscribers" example:"false"`                               // news and weather only, also send to the numbers subscribed through the bot, weather subscribers get the weather of their shared location
+	UsingLLM        bool     `json:"using_llm" example:"true"`                                  // options: true, false, summarize news or weather with llm
+	Typing          bool     `json:"typing" example:"false"`                                    // options: true, false, show typing in the chat for a time based on the message length before every message
+	Enabled         *bool    `json:"enabled" example:"true"`                                    // default true, disabled schedules are kept but never fired
+}
@@ -0,0 +1,17 @@
+package models
+
+type WatchlistReq struct {
+	Name            string   `json:"name" example:"Acme brand mentions"`                     // required, name to recognize the watchlist, shown in the alert
+	Query           string   `json:"query" example:"\"Acme Corp\" OR Globex"`                // required when domains and sources are empty, keywords or phrases, supports "exact match", AND, OR and NOT
+	SearchIn        string   `json:"search_in" example:"title,description"`                  // options: title, description, content, comma separated, all fields when empty
+	Domains         string   `json:"domains" example:"techcrunch.com,bbc.co.uk"`             // comma separated domains to restrict the search to
+	ExcludeDomains  string   `json:"exclude_domains" example:"example.com"`                  // comma separated domains to remove from the results
+	Sources         string   `json:"sources" example:"bbc-news,cnn"`                         // comma separated source ids from /news/sources to restrict the search to, 20 at most
+	Language        string   `json:"language" example:"en"`                                  // options: ar, de, en, es, fr, he, it, nl, no, pt, ru, sv, ud, zh, all languages when empty
+	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"` // list of numbers to alert, start with code number like 62 and not 0 like 08123456789, or group JIDs like 120363025246125486@g.us
+	Tags            []string `json:"tags" example:"pr-team"`                                 // contacts with at least one of the tags are alerted too, looked up on every poll
+	Session         string   `json:"session" example:"default"`                              // whatsapp session to send from, default session when empty
+	IntervalMinutes int      `json:"interval_minutes" example:"30"`                          // minutes between polls, 5 to 1440, default 30. Every poll is one NewsAPI request
+	Typing          bool     `json:"typing" example:"false"`                                 // options: true, false, show typing in the chat before the alert
+	Enabled         *bool    `json:"enabled" example:"true"`                                 // default true, disabled watchlists are kept but never polled
+}
@@ -1,20 +1,159 @@
 package models
 
+import "time"
+
 type NewsSendWhatsappReq struct {
-	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"` // list of numbers to send the news to and start with code number like 62 and not 0 like 08123456789
-	Category        string   `json:"category" example:"business"`                            // options: business, entertainment, general, health, science, sports, technology
+	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"` // list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us
+	Category        string   `json:"category" example:"business"`                            // options: business, entertainment, general, health, science, sports, technology. Category, country or sources is required
+	Country         string   `json:"country" example:"id"`                                   // ISO 3166-1 code of the country of the headlines, like us or id
+	Sources         string   `json:"sources" example:"bbc-news,cnn"`                         // comma separated source ids from /news/sources, 20 at most, can not be mixed with category or country
 	UsingLLM        bool     `json:"using_llm" example:"true"`                               // options: true, false, if set to true, the message news will be add with llm and if false, the message news will be add with the default message
+	Subscribers     bool     `json:"subscribers" example:"false"`                            // if set to true, also send to the numbers subscribed to the category through the bot, whatsapp_numbers, tags and contact_ids can then be empty
+	Rich            bool     `json:"rich" example:"false"`                                   // if set to true, every headline is sent as an image message with the article picture and the llm summary follows as a text message
+	Tags            []string `json:"tags" example:"team"`                                    // also send to the contacts with at least one of the tags
+	ContactIDs      []int64  `json:"contact_ids" example:"1,2"`                              // also send to the contacts with the ids
+	Typing          bool     `json:"typing" example:"false"`                                 // if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural
+	Session         string   `json:"session" example:"default"`                              // whatsapp session to send from, the default session when empty
+	SkipInvalid     bool     `json:"skip_invalid" example:"false"`                           // if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request
+	Async           bool     `json:"async" example:"false"`                                  // if set to true, return 202 with a job id right away and run the broadcast in the background
+}
+
+type NewsSearchSendWhatsappReq struct {
+	Query           string   `json:"query" example:"\"Acme Corp\" OR Globex"`                // keywords or phrase to search for, supports "exact match", AND, OR and NOT, required when domains and sources are empty
+	SearchIn        string   `json:"search_in" example:"title,description"`                  // comma separated fields to search the query in, options: title, description, content, default all of them
+	Domains         string   `json:"domains" example:"bbc.co.uk,techcrunch.com"`             // comma separated domains to restrict the search to
+	ExcludeDomains  string   `json:"exclude_domains" example:"example.com"`                  // comma separated domains to leave out
+	Sources         string   `json:"sources" example:"bbc-news,cnn"`                         // comma separated source ids from /news/sources to restrict the search to, 20 at most
+	Language        string   `json:"language" example:"en"`                                  // options: ar, de, en, es, fr, he, it, nl, no, pt, ru, sv, ud, zh, default all languages
+	From            string   `json:"from" example:"2025-04-01"`                              // oldest article, YYYY-MM-DD or YYYY-MM-DDTHH:MM:SSZ
+	To              string   `json:"to" example:"2025-04-07"`                                // newest article, YYYY-MM-DD or YYYY-MM-DDTHH:MM:SSZ
+	SortBy          string   `json:"sort_by" example:"publishedAt"`                          // options: relevancy, popularity, publishedAt, default publishedAt
+	Limit           int      `json:"limit" example:"10"`                                     // articles to send, 1 to 10, default 10
+	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"` // list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us
+	UsingLLM        bool     `json:"using_llm" example:"true"`                               // options: true, false, if set to true, the llm summary of the found articles is added at the end
+	Rich            bool     `json:"rich" example:"false"`                                   // if set to true, every article is sent as an image message with the article picture and the llm summary follows as a text message
+	Tags            []string `json:"tags" example:"team"`                                    // also send to the contacts with at least one of the tags
+	ContactIDs      []int64  `json:"contact_ids" example:"1,2"`                              // also send to the contacts with the ids
+	Typing          bool     `json:"typing" example:"false"`                                 // if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural
+	Session         string   `json:"session" example:"default"`                              // whatsapp session to send from, the default session when empty
+	SkipInvalid     bool     `json:"skip_invalid" example:"false"`                           // if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request
+	Async           bool     `json:"async" example:"false"`                                  // if set to true, return 202 with a job id right away and run the broadcast in the background
+}
+
+type FeedSendWhatsappReq struct {
+	FeedURL         string   `json:"feed_url" example:"https://www.theverge.com/rss/index.xml"` // required, url of an RSS 2.0 or Atom feed
+	Limit           int      `json:"limit" example:"10"`                                        // latest items to send, 1 to 10, default 10
+	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"`    // list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us
+	UsingLLM        bool     `json:"using_llm" example:"true"`                                  // options: true, false, if set to true, the llm summary of the items is added at the end
+	Rich            bool     `json:"rich" example:"false"`                                      // if set to true, every item with a picture is sent as an image message and the llm summary follows as a text message
+	Tags            []string `json:"tags" example:"team"`                                       // also send to the contacts with at least one of the tags
+	ContactIDs      []int64  `json:"contact_ids" example:"1,2"`                                 // also send to the contacts with the ids
+	Typing          bool     `json:"typing" example:"false"`                                    // if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural
+	Session         string   `json:"session" example:"default"`                                 // whatsapp session to send from, the default session when empty
+	SkipInvalid     bool     `json:"skip_invalid" example:"false"`                              // if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request
+	Async           bool     `json:"async" example:"false"`                                     // if set to true, return 202 with a job id right away and run the broadcast in the background
 }
 
 type WhatsappMessagesReq struct {
 	Messages        string   `json:"messages" example:"Hello, this is a test message"`       // message to be sent to the whatsapp numbers
-	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"` // list of numbers to send the news to and start with code number like 62 and not 0 like 08123456789
+	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"` // list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us
+	Tags            []string `json:"tags" example:"team"`                                    // also send to the contacts with at least one of the tags
+	ContactIDs      []int64  `json:"contact_ids" example:"1,2"`                              // also send to the contacts with the ids
+	Typing          bool     `json:"typing" example:"false"`                                 // if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural
+	Session         string   `json:"session" example:"default"`                              // whatsapp session to send from, the default session when empty
+	SkipInvalid     bool     `json:"skip_invalid" example:"false"`                           // if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request
+	Async           bool     `json:"async" example:"false"`                                  // if set to true, return 202 with a job id right away and send in the background
 }
 
 type WeatherSendWhatsappReq struct {
 	Type            string   `json:"type" example:"today"`                                   // required, options: today, tomorrow
 	Lat             float64  `json:"lat" example:"-6.2617"`                                  // required, latitude of the location
 	Lon             float64  `json:"lon" example:"106.8103"`                                 // required, longitude of the location
-	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"` // list of numbers to send the news to and start with code number like 62 and not 0 like 08123456789
+	WhatsappNumbers []string `json:"whatsapp_numbers" example:"6285727771234,6285667889887"` // list of numbers to send the news to with the country code like 6285727771234 or +62 857-2777-1234, a leading 0 gets the DEFAULT_COUNTRY_CODE, or group JIDs like 120363025246125486@g.us
 	UsingLLM        bool     `json:"using_llm" example:"true"`                               // options: true, false, if set to true, the message news will be add with llm and if false, the message news will be add with the default message
+	Subscribers     bool     `json:"subscribers" example:"false"`                            // if set to true, also send to the weather subscribers of the bot with the weather of their shared location, whatsapp_numbers, tags and contact_ids can then be empty
+	Tags            []string `json:"tags" example:"team"`                                    // also send to the contacts with at least one of the tags, contacts with a default location get the weather there
+	ContactIDs      []int64  `json:"contact_ids" example:"1,2"`                              // also send to the contacts with the ids, contacts with a default location get the weather there
+	Typing          bool     `json:"typing" example:"false"`                                 // if set to true, show typing in the chat for a time based on the message length before every message, slower but looks more natural
+	Session         string   `json:"session" example:"default"`                              // whatsapp session to send from, the default session when empty
+	SkipInvalid     bool     `json:"skip_invalid" example:"false"`                           // if set to true, numbers that are invalid or not on WhatsApp are skipped and reported instead of rejecting the request
+	Async           bool     `json:"async" example:"false"`                                  // if set to true, return 202 with a job id right away and run the broadcast in the background
+}
+
+const (
+	WhatsappSendStatusSent     = "sent"     // accepted by the WhatsApp server
+	WhatsappSendStatusQueued   = "queued"   // stored in the outbox but not tried yet
+	WhatsappSendStatusRetrying = "retrying" // first attempt failed, the outbox will retry it
+	WhatsappSendStatusDeferred = "deferred" // held back by the rate limiter, the outbox sends it at retry_at
+	WhatsappSendStatusFailed   = "failed"   // ran out of attempts and moved to the dead-letter state
+	WhatsappSendStatusInvalid  = "invalid"  // skipped because the number is invalid or not on WhatsApp, never sent
+)
+
+// WhatsappSendResult is the delivery report for one recipient of a send request
+type WhatsappSendResult struct {
+	Number    string     `json:"number" example:"6285727771234"`                      // recipient number the message was sent to
+	OutboxID  int64      `json:"outbox_id" example:"1"`                               // id of the message in the outbox, use it to inspect or replay the message
+	Status    string     `json:"status" example:"sent"`                               // options: sent, queued, deferred, retrying, failed, invalid
+	MessageID string     `json:"message_id,omitempty" example:"3EB0C431C26A1916E4A2"` // WhatsApp message ID, only set when the message is sent
+	Timestamp *time.Time `json:"timestamp,omitempty"`                                 // server timestamp of the sent message
+	RetryAt   *time.Time `json:"retry_at,omitempty"`                                  // when a deferred message is sent
+	Error     string     `json:"error,omitempty"`                                     // reason the message failed to send
+}
+
+type WhatsappGroup struct {
+	JID          string `json:"jid" example:"120363025246125486@g.us"` // use it in whatsapp_numbers to send to the group
+	Name         string `json:"name" example:"Team Alerts"`
+	Topic        string `json:"topic" example:"Daily alerts for the team"`
+	Participants int    `json:"participants" example:"12"`
+	IsAnnounce   bool   `json:"is_announce" example:"false"` // only admins can send messages
+}
+
+type WhatsappJoinGroupReq struct {
+	InviteLink string `json:"invite_link" example:"https://chat.whatsapp.com/AbCdEfGhIjKlMnOpQrStUv"` // required, invite link or only its code
+	Session    string `json:"session" example:"default"`                                              // whatsapp session that joins the group, the default session when empty
+}
+
+type WhatsappPairPhoneReq struct {
+	PhoneNumber string `json:"phone_number" example:"6285727771234"` // required, number of the phone that links the session, a leading 0 gets the DEFAULT_COUNTRY_CODE
+	Session     string `json:"session" example:"default"`            // whatsapp session to pair, the default session when empty
+}
+
+type WhatsappPairCode struct {
+	Session string `json:"session" example:"default"`
+	Code    string `json:"code" example:"ABCD-EFGH"` // enter it on the phone from WhatsApp > Linked devices > Link with phone number instead
+}
+
+type WhatsappCheckNumbersReq struct {
+	WhatsappNumbers []string `json:"whatsapp_numbers" example:"085727771234,+62 856-6788-9887"` // required, max 100 numbers or group JIDs to check
+	Session         string   `json:"session" example:"default"`                                 // whatsapp session used to check, the default session when empty
+}
+
+// WhatsappNumberCheck is the validation result of one number
+type WhatsappNumberCheck struct {
+	Input      string `json:"input" example:"085727771234"`   // number as given in the request
+	Number     string `json:"number" example:"6285727771234"` // normalized number, use it in whatsapp_numbers
+	IsGroup    bool   `json:"is_group" example:"false"`       // group JIDs are not checked with WhatsApp
+	Valid      bool   `json:"valid" example:"true"`           // the number can be sent to
+	OnWhatsApp bool   `json:"on_whatsapp" example:"true"`     // the number is registered on WhatsApp
+	Reason     string `json:"reason,omitempty"`               // why the number is not valid
+}
+
+type WhatsappSessionReq struct {
+	Name string `json:"name" example:"alerts"` // required, 1 to 32 letters, numbers, - or _
+}
+
+type WhatsappSession struct {
+	Name        string    `json:"name" example:"alerts"`
+	JID         string    `json:"jid,omitempty" example:"6285727771234:12@s.whatsapp.net"` // device of the session, empty until it is paired
+	IsConnected bool      `json:"is_connected" example:"true"`
+	IsLoggedIn  bool      `json:"is_logged_in" example:"true"`
+	QRReady     bool      `json:"qr_ready" example:"false"` // a QR code is waiting to be scanned, get it from the pair endpoint
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WhatsappSessionPair struct {
+	Name       string `json:"name" example:"alerts"`
+	IsLoggedIn bool   `json:"is_logged_in" example:"false"`
+	QRReady    bool   `json:"qr_ready" example:"true"`
+	QRCode     string `json:"qr_code,omitempty"` // scan it from WhatsApp > Linked devices, it rotates every few seconds
 }
@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/pkg/feeds"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// BuildFeedPayloads reads the RSS or Atom feed and turns its latest items, at most limit and never more than
+// a search sends, into messages the same way as BuildNewsPayloads. The llm summary uses the general news prompt.
+// A feed that can not be read or has no items is an invalid request, the url comes from the caller
+func (n *Notifier) BuildFeedPayloads(ctx context.Context, feed_url string, limit int, using_llm, rich bool, progress ProgressFunc) ([]outbox.Payload, error) {
+	progress.report(StageFetching)
+
+	feed, err := feeds.Fetch(feed_url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+
+	if len(feed.Articles) == 0 {
+		return nil, fmt.Errorf("%w: the feed has no items", ErrInvalidRequest)
+	}
+
+	if limit < 1 || limit > newsSearchLimit {
+		limit = newsSearchLimit
+	}
+	articles := feed.Articles[:min(limit, len(feed.Articles))]
+
+	title := feed.Title
+	if title == "" {
+		title = feed_url
+	}
+	header := fmt.Sprintf("📰 *LATEST FROM %s* 📰\n\n", strings.ToUpper(title))
+
+	return n.newsPayloads(header, fmt.Sprintf(poweredByFooter, title), articles, utils.NewsTypeGeneral, using_llm, rich, progress)
+}
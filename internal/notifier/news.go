@@ -0,0 +1,260 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/pkg/news"
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+// poweredByFooter ends the news messages with the provider the articles came from
+const poweredByFooter = "Powered by %s | Kelana Chandra Helyandika | kelanach.xyz"
+
+// fetchTopHeadlines gets the top headlines of the category, country or sources from the first news provider
+// that answers, with the name of that provider
+func (n *Notifier) fetchTopHeadlines(query news.Query) ([]newsapi.Article, string, error) {
+	// this will be adjust to my need for whastapp notifier that one req will be max get 10 top headlines for better expererience
+	query.Limit = 10
+
+	return n.headlines.TopHeadlines(query)
+}
+
+// NewsSources lists the newsapi sources matching the filters, the ids are what the sources filter of the broadcasts takes
+func (n *Notifier) NewsSources(query newsapi.NewsAPISourcesReq) ([]newsapi.Source, error) {
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+
+	return n.newsAPI.Sources(query)
+}
+
+// how many articles a search broadcast sends at most, like the top headlines
+const newsSearchLimit = 10
+
+// fetchEverything searches every article of newsapi, newest first unless the query sorts otherwise
+func (n *Notifier) fetchEverything(query newsapi.NewsAPIEverythingReq) ([]newsapi.Article, error) {
+	if query.SortBy == "" {
+		query.SortBy = "publishedAt"
+	}
+	if query.PageSize < 1 || query.PageSize > newsSearchLimit {
+		query.PageSize = newsSearchLimit
+	}
+	query.Page = 1
+
+	return n.newsAPI.Search(query)
+}
+
+// SearchNews searches every newsapi article with the query, newest first and at most newsSearchLimit articles
+func (n *Notifier) SearchNews(query newsapi.NewsAPIEverythingReq) ([]newsapi.Article, error) {
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+
+	return n.fetchEverything(query)
+}
+
+// BuildNewsAlert formats the new articles of a watchlist into one WhatsApp message
+func BuildNewsAlert(name string, articles []newsapi.Article) string {
+	message := fmt.Sprintf("🚨 *NEW NEWS: %s* 🚨\n\n", strings.ToUpper(name))
+	for i, article := range articles {
+		message += formatArticle(i, article) + "\n"
+	}
+
+	return message + fmt.Sprintf(poweredByFooter, "NewsAPI")
+}
+
+// formatArticle formats one article as the numbered entry used in the news message
+func formatArticle(i int, article newsapi.Article) string {
+	message := fmt.Sprintf("*%d. %s*\n", i+1, article.Title)
+	message += fmt.Sprintf("📄 *Source:* %s\n", article.Source.Name)
+
+	if article.Author != "" {
+		message += fmt.Sprintf("✍️ *Author:* %s\n", article.Author)
+	}
+
+	if article.PublishedAt != "" {
+		// Parse the ISO 8601 date format
+		// example of article.PublishedAt: "2025-04-04T14:19:00Z"
+		t, err := time.Parse(time.RFC3339, article.PublishedAt)
+		if err == nil {
+			// Format as a more readable date: e.g., "04 Apr 2025, 14:19"
+			formattedDate := t.Format("02 Jan 2006, 15:04")
+			message += fmt.Sprintf("📅 *Published:* %s\n", formattedDate)
+		} else {
+			// Fallback to original format if parsing fails
+			message += fmt.Sprintf("📅 *Published:* %s\n", article.PublishedAt)
+		}
+	}
+
+	if article.Description != "" {
+		message += fmt.Sprintf("📝 *Summary:* %s\n", article.Description)
+	}
+
+	message += fmt.Sprintf("🔗 *Read more:* %s\n", article.Url)
+
+	return message
+}
+
+// formatArticleCaption is the short caption put under the article image in rich mode
+func formatArticleCaption(i int, article newsapi.Article) string {
+	return fmt.Sprintf("*%d. %s*\n📄 *Source:* %s\n🔗 %s", i+1, article.Title, article.Source.Name, article.Url)
+}
+
+// summarizeNews asks the llm for the insights of the formatted news
+func (n *Notifier) summarizeNews(news_data string, news_type utils.NewsType) (string, error) {
+	prompt_news_summaries, err := utils.GenerateNewsSummariesPrompt(news_data, news_type)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate news summaries: %w", err)
+	}
+
+	// send to openai for summarization
+	summaries_news, err := n.askLLM(prompt_news_summaries)
+	if err != nil {
+		return "", fmt.Errorf("failed to get summaries from OpenAI: %w", err)
+	}
+
+	return fmt.Sprintf("🤖 *AI Summaries:*\n%s\n\n", summaries_news), nil
+}
+
+// BuildNewsPayloads gets the top headlines of the category, country or sources and turns them into the messages to send.
+// By default it is one text message with every headline and the llm summary at the end.
+// In rich mode every headline is its own image message from the article picture with the title, source
+// and link as caption, followed by one text message with the llm summary. Headlines without a picture,
+// or whose picture can not be fetched, are sent as text instead
+func (n *Notifier) BuildNewsPayloads(ctx context.Context, query news.Query, using_llm, rich bool, progress ProgressFunc) ([]outbox.Payload, error) {
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+
+	// headlines of sources have no category, they get the general summary prompt
+	news_type := utils.NewsTypeGeneral
+	if using_llm && query.Category != "" {
+		// check the category before calling any api, not every category has a summary prompt
+		var err error
+		news_type, err = utils.GetNewsType(query.Category)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+		}
+	}
+
+	progress.report(StageFetching)
+
+	articles, provider, err := n.fetchTopHeadlines(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(articles) == 0 {
+		return nil, fmt.Errorf("%w: no top headlines found", ErrInvalidRequest)
+	}
+
+	title := "TOP NEWS FROM " + query.Sources
+	if query.Sources == "" {
+		title = strings.Join(strings.Fields("TOP "+query.Category+" NEWS"), " ")
+		if query.Country != "" {
+			title += " IN " + query.Country
+		}
+	}
+	header := fmt.Sprintf("📰 *%s TODAY* 📰\n\n", strings.ToUpper(title))
+
+	return n.newsPayloads(header, fmt.Sprintf(poweredByFooter, provider), articles, news_type, using_llm, rich, progress)
+}
+
+// BuildNewsSearchPayloads searches every newsapi article with the keywords of the query, like a company name
+// or its competitors, and turns the matches into messages the same way as BuildNewsPayloads.
+// The llm summary uses the general news prompt
+func (n *Notifier) BuildNewsSearchPayloads(ctx context.Context, query newsapi.NewsAPIEverythingReq, using_llm, rich bool, progress ProgressFunc) ([]outbox.Payload, error) {
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+
+	progress.report(StageFetching)
+
+	articles, err := n.fetchEverything(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(articles) == 0 {
+		return nil, fmt.Errorf("%w: no news matches the search", ErrInvalidRequest)
+	}
+
+	topic := query.Q
+	if topic == "" {
+		topic = query.Domains
+	}
+	if topic == "" {
+		topic = query.Sources
+	}
+	header := fmt.Sprintf("🔎 *NEWS ABOUT %s* 🔎\n\n", strings.ToUpper(topic))
+
+	return n.newsPayloads(header, fmt.Sprintf(poweredByFooter, "NewsAPI"), articles, utils.NewsTypeGeneral, using_llm, rich, progress)
+}
+
+// newsPayloads formats the articles under the header into one text message, or into one image message per article
+// in rich mode, and adds the llm summary and the footer at the end
+func (n *Notifier) newsPayloads(header, footer string, articles []newsapi.Article, news_type utils.NewsType, using_llm, rich bool, progress ProgressFunc) ([]outbox.Payload, error) {
+	message_whatsapp := header
+	for i, article := range articles {
+		message_whatsapp += formatArticle(i, article) + "\n"
+	}
+
+	payloads := []outbox.Payload{}
+	if rich {
+		payloads = append(payloads, outbox.Payload{Type: outbox.TypeText, Body: strings.TrimSpace(header)})
+		for i, article := range articles {
+			payloads = append(payloads, articlePayload(i, article))
+		}
+	}
+
+	// continue using llm if using_llm is true
+	var summaries string
+	if using_llm {
+		progress.report(StageLLM)
+
+		llm_summaries, err := n.summarizeNews(message_whatsapp, news_type)
+		if err != nil {
+			return nil, err
+		}
+		summaries = llm_summaries
+	}
+
+	// in rich mode the last message only has the summary, the headlines are already sent as images
+	if rich {
+		return append(payloads, outbox.Payload{Type: outbox.TypeText, Body: summaries + footer}), nil
+	}
+
+	// add the summaries and footer to the message
+	message_whatsapp += summaries + footer
+
+	return []outbox.Payload{{Type: outbox.TypeText, Body: message_whatsapp}}, nil
+}
+
+// articlePayload is the image message of one headline, or a text message when the image is not usable
+func articlePayload(i int, article newsapi.Article) outbox.Payload {
+	if article.UrlToImage != "" {
+		data, mimetype, file_name, err := whatsapp.FetchMedia(article.UrlToImage)
+		if err == nil && strings.HasPrefix(mimetype, "image/") {
+			return outbox.Payload{
+				Type:          outbox.TypeImage,
+				Body:          formatArticleCaption(i, article),
+				Media:         data,
+				MediaMimeType: mimetype,
+				MediaFileName: file_name,
+			}
+		}
+
+		if err != nil {
+			log.Printf("Failed to fetch image of article %s: %s\n", article.Url, err.Error())
+		}
+	}
+
+	return outbox.Payload{Type: outbox.TypeText, Body: strings.TrimSpace(formatArticle(i, article))}
+}
@@ -0,0 +1,233 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/momokii/go-llmbridge/pkg/openai"
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/pkg/news"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+// pipeline stages reported through ProgressFunc while a broadcast is built and sent
+const (
+	StageFetching = "fetching" // getting data from the news or weather api
+	StageLLM      = "llm"      // waiting for the llm summary
+	StageSending  = "sending"  // messages are in the outbox and being sent
+)
+
+// how long Send waits for the first attempt of its messages before returning,
+// anything not sent by then is reported as queued and keeps going in the background
+const sendWaitTimeout = 30 * time.Second
+
+// ErrInvalidRequest is wrapped by errors caused by the request data instead of an upstream failure
+var ErrInvalidRequest = errors.New("invalid request")
+
+// ErrNotQueued is wrapped by send errors that happened before the messages were in the outbox,
+// other send errors leave the messages queued and the outbox sends them
+var ErrNotQueued = errors.New("messages were not queued")
+
+// ProgressFunc is called every time the pipeline moves to the next stage
+type ProgressFunc func(stage string)
+
+func (f ProgressFunc) report(stage string) {
+	if f != nil {
+		f(stage)
+	}
+}
+
+// Notifier builds the news and weather messages and delivers them through the outbox,
+// it is shared by the http handlers and the background jobs so both run the exact same pipeline.
+// The headlines come from the first news provider that answers, NewsAPI before the fallbacks
+type Notifier struct {
+	newsAPI             *news.NewsAPI
+	headlines           *news.Failover
+	openweather_api_key string
+	openaiClient        openai.OpenAI
+	outbox              *outbox.Outbox
+	sessions            *whatsapp.Manager
+}
+
+func New(
+	newsAPI *news.NewsAPI,
+	fallbackProviders []news.NewsProvider,
+	openweather_api_key string,
+	openaiClient openai.OpenAI,
+	outboxQueue *outbox.Outbox,
+	sessions *whatsapp.Manager,
+) (*Notifier, error) {
+
+	if newsAPI == nil {
+		return nil, fmt.Errorf("newsapi provider is required")
+	}
+
+	if outboxQueue == nil {
+		return nil, fmt.Errorf("outbox is required")
+	}
+
+	if sessions == nil {
+		return nil, fmt.Errorf("whatsapp sessions are required")
+	}
+
+	return &Notifier{
+		newsAPI:             newsAPI,
+		headlines:           news.NewFailover(append([]news.NewsProvider{newsAPI}, fallbackProviders...)...),
+		openweather_api_key: openweather_api_key,
+		openaiClient:        openaiClient,
+		outbox:              outboxQueue,
+		sessions:            sessions,
+	}, nil
+}
+
+// askLLM sends a single prompt to openai and returns the first content of the answer
+func (n *Notifier) askLLM(prompt string) (string, error) {
+	messages := []openai.OAMessageReq{
+		{
+			Role:    "user",
+			Content: prompt,
+		},
+	}
+
+	resp, err := n.openaiClient.OpenAIGetFirstContentDataResp(&messages, false, nil, false, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Content, nil
+}
+
+// sendResultFromOutbox converts the outbox state of a message into its delivery report
+func sendResultFromOutbox(msg outbox.Message) models.WhatsappSendResult {
+	result := models.WhatsappSendResult{
+		Number:   msg.Recipient,
+		OutboxID: msg.ID,
+		Error:    msg.LastError,
+	}
+
+	switch {
+	case msg.Status == outbox.StatusSent:
+		result.Status = models.WhatsappSendStatusSent
+		result.MessageID = msg.WAMessageID
+		result.Timestamp = msg.SentAt
+	case msg.Status == outbox.StatusDead:
+		result.Status = models.WhatsappSendStatusFailed
+	case msg.DeferredUntil != nil:
+		result.Status = models.WhatsappSendStatusDeferred
+		result.RetryAt = msg.DeferredUntil
+	case msg.Attempts > 0:
+		result.Status = models.WhatsappSendStatusRetrying
+	default:
+		result.Status = models.WhatsappSendStatusQueued
+	}
+
+	return result
+}
+
+// CheckRecipients normalizes the numbers and checks them with IsOnWhatsApp from the session, it returns the numbers to send to
+// without duplicates and a report with the invalid status for the rest. When WhatsApp can not be asked,
+// like while the account is not logged in, only the number format is checked and the outbox handles the rest
+func (n *Notifier) CheckRecipients(session string, numbers []string) ([]string, []models.WhatsappSendResult) {
+	var checks []whatsapp.NumberCheck
+
+	waClient, err := n.sessions.Get(session)
+	if err == nil {
+		checks, err = waClient.CheckNumbers(numbers)
+	}
+	if err != nil {
+		log.Println("Failed to check numbers on WhatsApp, checking the format only: " + err.Error())
+		checks = whatsapp.NormalizeRecipients(numbers)
+	}
+
+	valid := []string{}
+	invalid := []models.WhatsappSendResult{}
+	seen := map[string]bool{}
+	for _, check := range checks {
+		if !check.Valid {
+			invalid = append(invalid, models.WhatsappSendResult{
+				Number: check.Input,
+				Status: models.WhatsappSendStatusInvalid,
+				Error:  check.Reason,
+			})
+			continue
+		}
+
+		if !seen[check.Number] {
+			seen[check.Number] = true
+			valid = append(valid, check.Number)
+		}
+	}
+
+	return valid, invalid
+}
+
+// Send puts the text message for every number in the outbox of the session and waits a moment for the first attempt,
+// messages that are not sent by then stay in the outbox and are retried by the workers. The origin is kept for the history
+func (n *Notifier) Send(ctx context.Context, session string, origin outbox.Origin, message string, numbers []string) ([]models.WhatsappSendResult, error) {
+	return n.SendPayloads(ctx, session, origin, []outbox.Payload{{Type: outbox.TypeText, Body: message}}, numbers)
+}
+
+// SendPayloads is Send for a series of messages of any kind, like media with a caption.
+// Every number gets the payloads in the given order and there is one result per number and payload
+func (n *Notifier) SendPayloads(ctx context.Context, session string, origin outbox.Origin, payloads []outbox.Payload, numbers []string) ([]models.WhatsappSendResult, error) {
+	session = whatsapp.SessionName(session)
+
+	ids := make([]int64, 0, len(payloads)*len(numbers))
+	for _, payload := range payloads {
+		queued, err := n.outbox.Enqueue(ctx, session, origin, numbers, payload)
+		if err != nil {
+			if len(ids) == 0 {
+				return nil, fmt.Errorf("%w: %w", ErrNotQueued, err)
+			}
+			return nil, fmt.Errorf("failed to queue messages: %w", err)
+		}
+
+		for _, msg := range queued {
+			ids = append(ids, msg.ID)
+		}
+	}
+
+	wait_ctx, cancel := context.WithTimeout(ctx, sendWaitTimeout)
+	defer cancel()
+
+	attempted, err := n.outbox.AwaitFirstAttempt(wait_ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check queued messages: %w", err)
+	}
+
+	results := make([]models.WhatsappSendResult, 0, len(attempted))
+	for _, msg := range attempted {
+		results = append(results, sendResultFromOutbox(msg))
+	}
+
+	return results, nil
+}
+
+// Results reloads the delivery report of messages sent earlier, so callers see the retries done since
+func (n *Notifier) Results(ctx context.Context, results []models.WhatsappSendResult) ([]models.WhatsappSendResult, error) {
+	// numbers skipped by the validation never reached the outbox, their report does not change
+	ids := make([]int64, 0, len(results))
+	fresh := []models.WhatsappSendResult{}
+	for _, result := range results {
+		if result.OutboxID == 0 {
+			fresh = append(fresh, result)
+			continue
+		}
+		ids = append(ids, result.OutboxID)
+	}
+
+	messages, err := n.outbox.Store().GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, msg := range messages {
+		fresh = append(fresh, sendResultFromOutbox(msg))
+	}
+
+	return fresh, nil
+}
@@ -0,0 +1,242 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/internal/contacts"
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/internal/subscriptions"
+	"github.com/momokii/go-wa-notifier/pkg/openweatherapi"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+)
+
+// BuildWeatherMessage gets the weather of the location for today or tomorrow and formats it into a WhatsApp message,
+// written by the llm when using_llm is true and by the manual formatter otherwise
+func (n *Notifier) BuildWeatherMessage(ctx context.Context, report_type string, lat, lon float64, using_llm bool, progress ProgressFunc) (string, error) {
+
+	progress.report(StageFetching)
+
+	// get date, based on today or tomorrow
+	var date, reportType, localTime string
+	if report_type == "today" {
+		date = time.Now().Format("2006-01-02")
+		localTime = time.Now().Format("15:04:05")
+		reportType = "today"
+	} else {
+		date = time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+		localTime = time.Now().AddDate(0, 0, 1).Format("15:04:05")
+		reportType = "tomorrow"
+	}
+
+	weather_base_req := openweatherapi.OpenWeatherAPIV3OneCallBaseReq{
+		Lat:   lat,
+		Lon:   lon,
+		AppID: n.openweather_api_key,
+		Units: "metric", // using celcius as default for this endpoint
+	}
+
+	// first, get OVERVIEW DATA
+	weather_overview_req := openweatherapi.OpenWeatherAPIV3OneCallOverviewReq{
+		Date:                           date,
+		OpenWeatherAPIV3OneCallBaseReq: weather_base_req,
+	}
+
+	weather_overview, err := openweatherapi.OpenWeatherV3OneCallOverviewAPI(weather_overview_req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get weather overview: %w", err)
+	}
+
+	// second, get DAILY AGGREATE DATA
+	weather_daily_req := openweatherapi.OpenWeatherAPIV3OneCallDailySummaryReq{
+		Date:                           date,
+		OpenWeatherAPIV3OneCallBaseReq: weather_base_req,
+	}
+
+	weather_daily_aggregate, err := openweatherapi.OpenWeatherV3OneCallDailySummaryAPI(weather_daily_req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get weather daily aggregate: %w", err)
+	}
+
+	// third, get the weather data for hourly to get 24 hours data with onecall basic api
+	weather_onecall_24hr := openweatherapi.OpenWeatherAPIV3OneCallReq{
+		OpenWeatherAPIV3OneCallBaseReq: weather_base_req,
+		Exclude:                        []string{"current", "minutely", "daily", "alerts"}, // just get hourly data
+	}
+
+	weather_onecall_24hr_resp, err := openweatherapi.OpenWeatherV3OneCallAPI(weather_onecall_24hr)
+	if err != nil {
+		return "", fmt.Errorf("failed to get weather onecall 24 hours: %w", err)
+	}
+
+	// get 24 data hourly from the response
+	weather_24hr := weather_onecall_24hr_resp.Hourly
+	if len(weather_24hr) > 24 {
+		weather_24hr = weather_24hr[0:24]
+	}
+
+	weatherData := openweatherapi.WeatherDataAggregate{
+		Date:             date,
+		ReportType:       reportType,
+		Latitude:         lat,
+		Longitude:        lon,
+		WeatherOverview:  weather_overview.WeatherOverview,
+		Timezone:         weather_overview.TZ,
+		DailyAggregate:   weather_daily_aggregate,
+		HourlyForecast:   weather_24hr,
+		CurrentTimeLocal: localTime,
+	}
+
+	// check if using llm or not, if not just format the weather data manually
+	if !using_llm {
+		return utils.FormatWeatherMessageManual(&weatherData), nil
+	}
+
+	progress.report(StageLLM)
+
+	// generate prompt and send to openai for summarization
+	weather_ai, err := n.askLLM(utils.GenerateWeatherPrompt(&weatherData))
+	if err != nil {
+		return "", fmt.Errorf("failed to get weather summary: %w", err)
+	}
+
+	// format response to message whatsapp
+	return utils.FormatWeatherMessage(weather_ai, &weatherData), nil
+}
+
+// WeatherTarget is a location and the numbers that get its weather
+type WeatherTarget struct {
+	Lat     float64
+	Lon     float64
+	Numbers []string
+}
+
+// SendWeather builds the weather message of every target location and sends it to the numbers of that location,
+// written by the llm when origin.UsingLLM is true. It fails only when no message can be built or queued,
+// numbers of a location that fails are reported as failed
+func (n *Notifier) SendWeather(ctx context.Context, session string, origin outbox.Origin, report_type string, targets []WeatherTarget, progress ProgressFunc) ([]models.WhatsappSendResult, error) {
+	origin.Broadcast = outbox.BroadcastWeather
+
+	type built struct {
+		message string
+		target  WeatherTarget
+	}
+
+	messages := []built{}
+	results := []models.WhatsappSendResult{}
+	var build_err error
+	for _, target := range targets {
+		message, err := n.BuildWeatherMessage(ctx, report_type, target.Lat, target.Lon, origin.UsingLLM, progress)
+		if err != nil {
+			build_err = err
+			for _, number := range target.Numbers {
+				results = append(results, models.WhatsappSendResult{
+					Number: number,
+					Status: models.WhatsappSendStatusFailed,
+					Error:  err.Error(),
+				})
+			}
+			continue
+		}
+
+		messages = append(messages, built{message: message, target: target})
+	}
+
+	if len(messages) == 0 && build_err != nil {
+		return nil, build_err
+	}
+
+	progress.report(StageSending)
+	queued := false
+	var send_err error
+	for _, item := range messages {
+		sent, err := n.Send(ctx, session, origin, item.message, item.target.Numbers)
+		if err != nil {
+			// the other locations may already be queued, dropping their results would have them sent again on a retry
+			send_err = err
+			if !errors.Is(err, ErrNotQueued) {
+				queued = true
+			}
+			for _, number := range item.target.Numbers {
+				results = append(results, models.WhatsappSendResult{
+					Number: number,
+					Status: models.WhatsappSendStatusFailed,
+					Error:  err.Error(),
+				})
+			}
+			continue
+		}
+
+		queued = true
+		results = append(results, sent...)
+	}
+
+	if !queued && send_err != nil {
+		return nil, send_err
+	}
+
+	return results, nil
+}
+
+// SubscriberTargets turns the weather subscribers grouped by location into targets,
+// numbers in skip are left out because they already get the weather of the requested location
+func SubscriberTargets(groups []subscriptions.WeatherGroup, skip []string) []WeatherTarget {
+	skipped := map[string]bool{}
+	for _, number := range skip {
+		skipped[number] = true
+	}
+
+	targets := []WeatherTarget{}
+	for _, group := range groups {
+		target := WeatherTarget{Lat: group.Lat, Lon: group.Lon}
+		for _, number := range group.Numbers {
+			if !skipped[number] {
+				target.Numbers = append(target.Numbers, number)
+			}
+		}
+
+		if len(target.Numbers) > 0 {
+			targets = append(targets, target)
+		}
+	}
+
+	return targets
+}
+
+// ContactTargets groups the numbers by location, contacts with a default location get the weather there
+// and every other number gets the weather at lat and lon
+func ContactTargets(lat, lon float64, numbers []string, contact_list []contacts.Contact) []WeatherTarget {
+	located := map[string]contacts.Contact{}
+	for _, contact := range contact_list {
+		if contact.HasLocation() {
+			located[contact.Number] = contact
+		}
+	}
+
+	targets := []WeatherTarget{{Lat: lat, Lon: lon}}
+	index := map[[2]float64]int{{lat, lon}: 0}
+	for _, number := range numbers {
+		location := [2]float64{lat, lon}
+		if contact, ok := located[number]; ok {
+			location = [2]float64{*contact.Lat, *contact.Lon}
+		}
+
+		i, ok := index[location]
+		if !ok {
+			i = len(targets)
+			index[location] = i
+			targets = append(targets, WeatherTarget{Lat: location[0], Lon: location[1]})
+		}
+		targets[i].Numbers = append(targets[i].Numbers, number)
+	}
+
+	// the requested location is dropped when every number has its own
+	if len(targets[0].Numbers) == 0 {
+		targets = targets[1:]
+	}
+
+	return targets
+}
@@ -0,0 +1,253 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// Sender delivers one outbox message to WhatsApp
+type Sender interface {
+	Send(ctx context.Context, msg Message) (SendResult, error)
+}
+
+// Outbox is a durable queue of outbound messages backed by postgres and drained by a pool of workers.
+// Failed sends are retried with exponential backoff until MaxAttempts, after that the message is dead
+// and only sent again when replayed
+type Outbox struct {
+	store  *Store
+	sender Sender
+	opts   Options
+	wake   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func New(store *Store, sender Sender, opts Options) *Outbox {
+	defaults := DefaultOptions()
+	if opts.Workers < 1 {
+		opts.Workers = defaults.Workers
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = defaults.MaxAttempts
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaults.BaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaults.MaxBackoff
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaults.PollInterval
+	}
+	if opts.StaleAfter <= 0 {
+		opts.StaleAfter = defaults.StaleAfter
+	}
+	if opts.MediaRetention <= 0 {
+		opts.MediaRetention = defaults.MediaRetention
+	}
+
+	return &Outbox{
+		store:  store,
+		sender: sender,
+		opts:   opts,
+		wake:   make(chan struct{}, opts.Workers),
+	}
+}
+
+func (o *Outbox) Store() *Store {
+	return o.store
+}
+
+// Start runs the workers until ctx is cancelled
+func (o *Outbox) Start(ctx context.Context) {
+	// messages left in sending by a previous process will never finish, release them first
+	if n, err := o.store.releaseStale(ctx, 0); err != nil {
+		log.Println("Outbox: failed to release stale messages: " + err.Error())
+	} else if n > 0 {
+		log.Printf("Outbox: released %d stale messages\n", n)
+	}
+
+	for i := 0; i < o.opts.Workers; i++ {
+		o.wg.Add(1)
+		go o.worker(ctx)
+	}
+
+	o.wg.Add(1)
+	go o.janitor(ctx)
+}
+
+// Wait blocks until all workers have stopped, call it after cancelling the ctx given to Start
+func (o *Outbox) Wait() {
+	o.wg.Wait()
+}
+
+// notify wakes idle workers so new messages are sent without waiting for the next poll
+func (o *Outbox) notify() {
+	for i := 0; i < o.opts.Workers; i++ {
+		select {
+		case o.wake <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+func (o *Outbox) worker(ctx context.Context) {
+	defer o.wg.Done()
+
+	ticker := time.NewTicker(o.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// drain every due message before going idle
+		for ctx.Err() == nil {
+			msg, ok, err := o.store.claimDue(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Println("Outbox: failed to claim message: " + err.Error())
+				}
+				break
+			}
+			if !ok {
+				break
+			}
+
+			o.process(ctx, msg)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// janitor periodically releases messages stuck in sending and removes the media no message needs anymore
+func (o *Outbox) janitor(ctx context.Context) {
+	defer o.wg.Done()
+
+	ticker := time.NewTicker(o.opts.StaleAfter)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := o.store.releaseStale(ctx, o.opts.StaleAfter); err != nil && ctx.Err() == nil {
+				log.Println("Outbox: failed to release stale messages: " + err.Error())
+			}
+
+			if n, err := o.store.pruneMedia(ctx, o.opts.MediaRetention); err != nil && ctx.Err() == nil {
+				log.Println("Outbox: failed to remove old media: " + err.Error())
+			} else if n > 0 {
+				log.Printf("Outbox: removed %d media files\n", n)
+			}
+		}
+	}
+}
+
+func (o *Outbox) process(ctx context.Context, msg Message) {
+	// the result is written with a fresh context so a shutdown mid-send does not leave the row claimed
+	result, err := o.sender.Send(ctx, msg)
+	if err == nil {
+		if err := o.store.markSent(context.Background(), msg.ID, result); err != nil {
+			log.Printf("Outbox: failed to mark message %d as sent: %s\n", msg.ID, err.Error())
+		}
+		return
+	}
+
+	var deferred *DeferredError
+	if errors.As(err, &deferred) {
+		if err := o.store.markDeferred(context.Background(), msg.ID, deferred.RetryAt); err != nil {
+			log.Printf("Outbox: failed to defer message %d: %s\n", msg.ID, err.Error())
+		}
+		return
+	}
+
+	attempts := msg.Attempts + 1
+	dead := attempts >= msg.MaxAttempts
+	if dead {
+		log.Printf("Outbox: message %d to %s is dead after %d attempts: %s\n", msg.ID, msg.Recipient, attempts, err.Error())
+	} else {
+		log.Printf("Outbox: attempt %d for message %d to %s failed: %s\n", attempts, msg.ID, msg.Recipient, err.Error())
+	}
+
+	if err := o.store.markFailed(context.Background(), msg.ID, err, time.Now().Add(o.backoff(attempts)), dead); err != nil {
+		log.Printf("Outbox: failed to mark message %d as failed: %s\n", msg.ID, err.Error())
+	}
+}
+
+// backoff returns the wait before the next retry, BaseBackoff doubled per attempt and capped at MaxBackoff
+func (o *Outbox) backoff(attempts int) time.Duration {
+	wait := o.opts.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= o.opts.MaxBackoff {
+			return o.opts.MaxBackoff
+		}
+	}
+
+	return wait
+}
+
+// Enqueue stores one message per recipient to be sent from the session and wakes the workers to send them,
+// the origin is kept with the messages for the history
+func (o *Outbox) Enqueue(ctx context.Context, session string, origin Origin, recipients []string, payload Payload) ([]Message, error) {
+	messages, err := o.store.Enqueue(ctx, session, origin, recipients, payload, o.opts.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	o.notify()
+
+	return messages, nil
+}
+
+// Replay sends a dead message again with a fresh set of attempts
+func (o *Outbox) Replay(ctx context.Context, id int64) (Message, error) {
+	msg, err := o.store.Replay(ctx, id)
+	if err != nil {
+		return Message{}, err
+	}
+
+	o.notify()
+
+	return msg, nil
+}
+
+// AwaitFirstAttempt polls the given messages until each one has been tried at least once,
+// or deferred by the rate limiter, or ctx is done, and returns their latest state
+func (o *Outbox) AwaitFirstAttempt(ctx context.Context, ids []int64) ([]Message, error) {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		messages, err := o.store.GetMany(context.Background(), ids)
+		if err != nil {
+			return nil, err
+		}
+
+		done := true
+		for _, msg := range messages {
+			if msg.Attempts == 0 && msg.Status != StatusDead && msg.DeferredUntil == nil {
+				done = false
+				break
+			}
+		}
+
+		if done {
+			return messages, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return messages, nil
+		case <-ticker.C:
+		}
+	}
+}
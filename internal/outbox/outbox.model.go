@@ -0,0 +1,150 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+const (
+	StatusPending = "pending" // waiting for the first attempt or for the next retry
+	StatusSending = "sending" // claimed by a worker
+	StatusSent    = "sent"    // accepted by the WhatsApp server
+	StatusDead    = "dead"    // ran out of attempts, only sent again when replayed
+)
+
+// broadcast types of the history, what kind of message was sent
+const (
+	BroadcastNews    = "news"
+	BroadcastWeather = "weather"
+	BroadcastCustom  = "custom"
+	BroadcastMedia   = "media"
+	BroadcastReply   = "reply" // answer of the bot to a command
+)
+
+// sources of the history, what asked for the message
+const (
+	SourceAPI       = "api"
+	SourceSchedule  = "schedule"
+	SourceBot       = "bot"
+	SourceWatchlist = "watchlist" // alert of a keyword watchlist
+)
+
+// receipt states after sent, a message only moves forward in this order
+var receipts = []string{whatsapp.ReceiptDelivered, whatsapp.ReceiptRead, whatsapp.ReceiptPlayed}
+
+// message types, every type other than text carries a media file and uses the body as its caption
+const (
+	TypeText     = "text"
+	TypeImage    = "image"
+	TypeDocument = "document"
+	TypeAudio    = "audio"
+	TypeVideo    = "video"
+)
+
+// Payload is the content of a message before it is put in the outbox for its recipients
+type Payload struct {
+	Type          string // default text
+	Body          string // text of the message or the caption of the media
+	Media         []byte
+	MediaMimeType string
+	MediaFileName string
+}
+
+// Origin tells why messages were sent and how they are sent, it is stored with every message for the history
+type Origin struct {
+	Broadcast string // options: news, weather, custom, media, reply
+	Source    string // options: api, schedule, bot, watchlist
+	SourceRef string // request id of the api call, schedule id, bot command or watchlist id
+	UsingLLM  bool   // the message was written by the llm
+	Typing    bool   // show typing in the chat before every message is sent
+}
+
+// Media is a file shared by every outbox message of the same payload
+type Media struct {
+	ID       int64
+	Data     []byte
+	MimeType string
+	FileName string
+}
+
+// Message is one outbound WhatsApp message to a single recipient stored in the outbox table
+type Message struct {
+	ID            int64      `json:"id" example:"1"`
+	Session       string     `json:"session" example:"default"` // whatsapp session the message is sent from
+	Recipient     string     `json:"recipient" example:"6285727771234"`
+	Type          string     `json:"type" example:"text"` // options: text, image, document, audio, video
+	Body          string     `json:"body" example:"Hello, this is a test message"`
+	Broadcast     string     `json:"broadcast" example:"weather"`                                         // options: news, weather, custom, media, reply
+	Source        string     `json:"source" example:"api"`                                                // options: api, schedule, bot, watchlist
+	SourceRef     string     `json:"source_ref,omitempty" example:"b2c4f3a0-1c7e-4b6e-9a55-5f0e2c1d8a11"` // request id of the api call, schedule id, bot command or watchlist id
+	UsingLLM      bool       `json:"using_llm" example:"true"`
+	Typing        bool       `json:"typing" example:"false"` // typing is shown in the chat before the message is sent
+	MediaID       *int64     `json:"media_id,omitempty"`
+	Status        string     `json:"status" example:"sent"` // options: pending, sending, sent, dead
+	Attempts      int        `json:"attempts" example:"1"`
+	MaxAttempts   int        `json:"max_attempts" example:"5"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	WAMessageID   string     `json:"wa_message_id,omitempty" example:"3EB0C431C26A1916E4A2"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	DeferredUntil *time.Time `json:"deferred_until,omitempty"`         // set while the rate limiter holds the message back, cleared on the next attempt
+	Receipt       string     `json:"receipt,omitempty" example:"read"` // options: delivered, read, played, empty until the recipient sends the first receipt
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	ReadAt        *time.Time `json:"read_at,omitempty"`
+	PlayedAt      *time.Time `json:"played_at,omitempty"`  // audio and video only
+	State         string     `json:"state" example:"read"` // status moved on by the receipts, options: pending, sending, sent, delivered, read, played, dead
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Filter narrows the message history, empty fields are not filtered on
+type Filter struct {
+	Session   string
+	Recipient string
+	Broadcast string    // options: news, weather, custom, media, reply
+	State     string    // options: pending, sending, sent, delivered, read, played, dead
+	From      time.Time // created at or after
+	To        time.Time // created before
+	Before    int64     // cursor, only messages with a lower id
+}
+
+// DeferredError is returned by a Sender that is not allowed to send the message yet, like when the rate limit is reached.
+// The message waits until RetryAt without using one of its attempts
+type DeferredError struct {
+	RetryAt time.Time
+	Reason  string
+}
+
+func (e *DeferredError) Error() string {
+	return e.Reason
+}
+
+// SendResult is what the WhatsApp server returned for a sent message
+type SendResult struct {
+	MessageID string
+	Timestamp time.Time
+}
+
+type Options struct {
+	Workers      int           // number of concurrent workers sending from the outbox
+	MaxAttempts  int           // attempts before a message is moved to the dead-letter state
+	BaseBackoff  time.Duration // wait before the first retry, doubled on every next retry
+	MaxBackoff   time.Duration // upper bound of the wait between retries
+	PollInterval time.Duration // how often idle workers look for due messages
+	StaleAfter   time.Duration // messages claimed longer than this (e.g. process died mid-send) are released back to pending
+	// media of messages that are all sent is removed right away, media with dead messages is kept this long so they can be replayed
+	MediaRetention time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Workers:        4,
+		MaxAttempts:    5,
+		BaseBackoff:    10 * time.Second,
+		MaxBackoff:     30 * time.Minute,
+		PollInterval:   2 * time.Second,
+		StaleAfter:     5 * time.Minute,
+		MediaRetention: 7 * 24 * time.Hour,
+	}
+}
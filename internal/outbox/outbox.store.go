@@ -0,0 +1,401 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrNotFound = errors.New("outbox message not found")
+
+const messageColumns = `id, session, recipient, message_type, body, broadcast, source, source_ref, using_llm, typing, media_id, status, attempts, max_attempts, next_attempt_at,
+	last_error, wa_message_id, sent_at, deferred_until, receipt, delivered_at, read_at, played_at, created_at, updated_at`
+
+// messageState is the status of a sent message moved on by its receipts
+const messageState = `CASE WHEN status = 'sent' AND receipt <> '' THEN receipt ELSE status END`
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Migrate creates the outbox table and indexes if they do not exist yet
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS wa_outbox (
+			id              BIGSERIAL PRIMARY KEY,
+			recipient       TEXT        NOT NULL,
+			body            TEXT        NOT NULL,
+			status          TEXT        NOT NULL DEFAULT 'pending',
+			attempts        INT         NOT NULL DEFAULT 0,
+			max_attempts    INT         NOT NULL,
+			next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			last_error      TEXT        NOT NULL DEFAULT '',
+			wa_message_id   TEXT        NOT NULL DEFAULT '',
+			sent_at         TIMESTAMPTZ,
+			locked_at       TIMESTAMPTZ,
+			created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS wa_outbox_due_idx ON wa_outbox (status, next_attempt_at);
+		CREATE INDEX IF NOT EXISTS wa_outbox_recipient_idx ON wa_outbox (recipient, id);
+
+		CREATE TABLE IF NOT EXISTS wa_outbox_media (
+			id         BIGSERIAL PRIMARY KEY,
+			data       BYTEA       NOT NULL,
+			mimetype   TEXT        NOT NULL,
+			file_name  TEXT        NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS message_type TEXT NOT NULL DEFAULT 'text';
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS media_id BIGINT REFERENCES wa_outbox_media (id);
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS session TEXT NOT NULL DEFAULT 'default';
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS receipt TEXT NOT NULL DEFAULT '';
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMPTZ;
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS read_at TIMESTAMPTZ;
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS played_at TIMESTAMPTZ;
+		CREATE INDEX IF NOT EXISTS wa_outbox_media_idx ON wa_outbox (media_id) WHERE media_id IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS wa_outbox_wa_message_idx ON wa_outbox (wa_message_id) WHERE wa_message_id <> '';
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS broadcast TEXT NOT NULL DEFAULT 'custom';
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'api';
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS source_ref TEXT NOT NULL DEFAULT '';
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS using_llm BOOLEAN NOT NULL DEFAULT false;
+		CREATE INDEX IF NOT EXISTS wa_outbox_created_idx ON wa_outbox (created_at);
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS deferred_until TIMESTAMPTZ;
+		ALTER TABLE wa_outbox ADD COLUMN IF NOT EXISTS typing BOOLEAN NOT NULL DEFAULT false;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate outbox table: %w", err)
+	}
+
+	return nil
+}
+
+func scanMessage(row interface{ Scan(dest ...any) error }) (Message, error) {
+	var msg Message
+	err := row.Scan(
+		&msg.ID, &msg.Session, &msg.Recipient, &msg.Type, &msg.Body, &msg.Broadcast, &msg.Source, &msg.SourceRef, &msg.UsingLLM, &msg.Typing, &msg.MediaID, &msg.Status, &msg.Attempts, &msg.MaxAttempts, &msg.NextAttemptAt,
+		&msg.LastError, &msg.WAMessageID, &msg.SentAt, &msg.DeferredUntil, &msg.Receipt, &msg.DeliveredAt, &msg.ReadAt, &msg.PlayedAt,
+		&msg.CreatedAt, &msg.UpdatedAt,
+	)
+
+	msg.State = msg.Status
+	if msg.Status == StatusSent && msg.Receipt != "" {
+		msg.State = msg.Receipt
+	}
+
+	return msg, err
+}
+
+func scanMessages(rows *sql.Rows) ([]Message, error) {
+	defer rows.Close()
+
+	messages := []Message{}
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// Enqueue inserts one pending message per recipient of the session in a single transaction,
+// the media of the payload is stored once and shared by all of them
+func (s *Store) Enqueue(ctx context.Context, session string, origin Origin, recipients []string, payload Payload, max_attempts int) ([]Message, error) {
+	if payload.Type == "" {
+		payload.Type = TypeText
+	}
+
+	if origin.Broadcast == "" {
+		origin.Broadcast = BroadcastCustom
+	}
+
+	if origin.Source == "" {
+		origin.Source = SourceAPI
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var media_id *int64
+	if payload.Type != TypeText {
+		var id int64
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO wa_outbox_media (data, mimetype, file_name) VALUES ($1, $2, $3) RETURNING id`,
+			payload.Media, payload.MediaMimeType, payload.MediaFileName,
+		).Scan(&id); err != nil {
+			return nil, err
+		}
+		media_id = &id
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO wa_outbox (session, recipient, message_type, body, broadcast, source, source_ref, using_llm, typing, media_id, max_attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING `+messageColumns)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	messages := make([]Message, 0, len(recipients))
+	for _, recipient := range recipients {
+		msg, err := scanMessage(stmt.QueryRowContext(
+			ctx, session, recipient, payload.Type, payload.Body, origin.Broadcast, origin.Source, origin.SourceRef, origin.UsingLLM, origin.Typing, media_id, max_attempts,
+		))
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (s *Store) GetMedia(ctx context.Context, id int64) (Media, error) {
+	media := Media{ID: id}
+	err := s.db.QueryRowContext(ctx, `SELECT data, mimetype, file_name FROM wa_outbox_media WHERE id = $1`, id).
+		Scan(&media.Data, &media.MimeType, &media.FileName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Media{}, fmt.Errorf("media %d not found", id)
+	}
+
+	return media, err
+}
+
+// claimDue marks the oldest due message as sending and returns it.
+// A message is only claimed when no older message from the same session to the same recipient is still waiting,
+// so every recipient gets their messages in the order they were enqueued
+func (s *Store) claimDue(ctx context.Context) (Message, bool, error) {
+	row := s.db.QueryRowContext(ctx, `
+		UPDATE wa_outbox SET status = 'sending', locked_at = now(), updated_at = now()
+		WHERE id = (
+			SELECT o.id FROM wa_outbox o
+			WHERE o.status = 'pending' AND o.next_attempt_at <= now()
+				AND NOT EXISTS (
+					SELECT 1 FROM wa_outbox p
+					WHERE p.session = o.session AND p.recipient = o.recipient AND p.id < o.id AND p.status IN ('pending', 'sending')
+				)
+			ORDER BY o.id
+			LIMIT 1
+			FOR UPDATE OF o SKIP LOCKED
+		)
+		RETURNING `+messageColumns)
+
+	msg, err := scanMessage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Message{}, false, nil
+	}
+	if err != nil {
+		return Message{}, false, err
+	}
+
+	return msg, true, nil
+}
+
+func (s *Store) markSent(ctx context.Context, id int64, result SendResult) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE wa_outbox
+		SET status = 'sent', attempts = attempts + 1, wa_message_id = $2, sent_at = $3,
+			last_error = '', deferred_until = NULL, locked_at = NULL, updated_at = now()
+		WHERE id = $1`, id, result.MessageID, result.Timestamp)
+	return err
+}
+
+// markFailed records a failed attempt, the message goes back to pending until next_attempt_at
+// or to the dead-letter state when dead is true
+func (s *Store) markFailed(ctx context.Context, id int64, send_err error, next_attempt_at time.Time, dead bool) error {
+	status := StatusPending
+	if dead {
+		status = StatusDead
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE wa_outbox
+		SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4,
+			deferred_until = NULL, locked_at = NULL, updated_at = now()
+		WHERE id = $1`, id, status, send_err.Error(), next_attempt_at)
+	return err
+}
+
+// markDeferred puts the message back to pending until retry_at without counting an attempt,
+// the message was not sent because the sender was not allowed to yet
+func (s *Store) markDeferred(ctx context.Context, id int64, retry_at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE wa_outbox
+		SET status = 'pending', next_attempt_at = $2, deferred_until = $2, locked_at = NULL, updated_at = now()
+		WHERE id = $1`, id, retry_at)
+	return err
+}
+
+// releaseStale puts messages that have been claimed for too long back to pending
+func (s *Store) releaseStale(ctx context.Context, older_than time.Duration) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE wa_outbox SET status = 'pending', locked_at = NULL, updated_at = now()
+		WHERE status = 'sending' AND locked_at < $1`, time.Now().Add(-older_than))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// pruneMedia removes the media that no pending or sending message uses, right away when every message of it was sent
+// and after the retention when some are dead. The messages keep their row without the media
+func (s *Store) pruneMedia(ctx context.Context, retention time.Duration) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT m.id FROM wa_outbox_media m
+		WHERE NOT EXISTS (SELECT 1 FROM wa_outbox o WHERE o.media_id = m.id AND o.status IN ('pending', 'sending'))
+			AND (m.created_at < $1 OR NOT EXISTS (SELECT 1 FROM wa_outbox o WHERE o.media_id = m.id AND o.status <> 'sent'))
+		ORDER BY m.id
+		LIMIT 100
+		FOR UPDATE OF m SKIP LOCKED`, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE wa_outbox SET media_id = NULL WHERE media_id = ANY($1)`, pq.Array(ids)); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM wa_outbox_media WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+
+	n, _ := res.RowsAffected()
+
+	return n, tx.Commit()
+}
+
+func (s *Store) Get(ctx context.Context, id int64) (Message, error) {
+	msg, err := scanMessage(s.db.QueryRowContext(ctx, `SELECT `+messageColumns+` FROM wa_outbox WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Message{}, ErrNotFound
+	}
+
+	return msg, err
+}
+
+func (s *Store) GetMany(ctx context.Context, ids []int64) ([]Message, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+messageColumns+` FROM wa_outbox WHERE id = ANY($1) ORDER BY id`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMessages(rows)
+}
+
+// List returns messages newest first, filtered by status and session when they are not empty
+func (s *Store) List(ctx context.Context, status, session string, limit, offset int) ([]Message, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+messageColumns+` FROM wa_outbox
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR session = $2)
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4`, status, session, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMessages(rows)
+}
+
+// History returns messages newest first narrowed by the filter, the id of the last message
+// is the Before cursor of the next page
+func (s *Store) History(ctx context.Context, filter Filter, limit int) ([]Message, error) {
+	var from, to *time.Time
+	if !filter.From.IsZero() {
+		from = &filter.From
+	}
+	if !filter.To.IsZero() {
+		to = &filter.To
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+messageColumns+` FROM wa_outbox
+		WHERE ($1 = '' OR session = $1) AND ($2 = '' OR recipient = $2) AND ($3 = '' OR broadcast = $3)
+			AND ($4 = '' OR `+messageState+` = $4)
+			AND ($5::timestamptz IS NULL OR created_at >= $5) AND ($6::timestamptz IS NULL OR created_at < $6)
+			AND ($7 = 0 OR id < $7)
+		ORDER BY id DESC
+		LIMIT $8`, filter.Session, filter.Recipient, filter.Broadcast, filter.State, from, to, filter.Before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMessages(rows)
+}
+
+// MarkReceipt records a receipt for the sent messages of the session with the WhatsApp message ids.
+// A message only moves forward, so a late delivered receipt does not undo read, and the first time of every receipt is kept
+func (s *Store) MarkReceipt(ctx context.Context, session string, wa_message_ids []string, receipt string, at time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE wa_outbox
+		SET receipt = CASE WHEN array_position($5::text[], $3) > COALESCE(array_position($5::text[], receipt), 0) THEN $3 ELSE receipt END,
+			delivered_at = COALESCE(delivered_at, $4),
+			read_at = CASE WHEN $3 IN ('read', 'played') THEN COALESCE(read_at, $4) ELSE read_at END,
+			played_at = CASE WHEN $3 = 'played' THEN COALESCE(played_at, $4) ELSE played_at END,
+			updated_at = now()
+		WHERE session = $1 AND wa_message_id = ANY($2)`,
+		session, pq.Array(wa_message_ids), receipt, at, pq.Array(receipts))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Replay moves a dead message back to pending with a fresh set of attempts
+func (s *Store) Replay(ctx context.Context, id int64) (Message, error) {
+	msg, err := scanMessage(s.db.QueryRowContext(ctx, `
+		UPDATE wa_outbox
+		SET status = 'pending', attempts = 0, next_attempt_at = now(), updated_at = now()
+		WHERE id = $1 AND status = 'dead'
+		RETURNING `+messageColumns, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Message{}, ErrNotFound
+	}
+
+	return msg, err
+}
@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+	"go.mau.fi/whatsmeow"
+)
+
+// WhatsappSender sends outbox messages with the client of the message session
+type WhatsappSender struct {
+	Store    *Store            // used to load the media of non text messages
+	Sessions *whatsapp.Manager // clients of the whatsapp sessions
+}
+
+func (s WhatsappSender) Send(ctx context.Context, msg Message) (SendResult, error) {
+	waClient, err := s.Sessions.Get(msg.Session)
+	if err != nil {
+		return SendResult{}, fmt.Errorf("failed to initiate WhatsApp: %w", err)
+	}
+
+	if !waClient.IsConnected() {
+		return SendResult{}, fmt.Errorf("WhatsApp client is not connected")
+	}
+
+	var resp whatsmeow.SendResponse
+	opts := whatsapp.SendOptions{Typing: msg.Typing}
+	switch {
+	case msg.Type == TypeText:
+		resp, err = waClient.SendMessage(msg.Recipient, msg.Body, opts)
+	case msg.MediaID == nil:
+		// the media of dead messages is removed after the retention, a late replay can not send it anymore
+		return SendResult{}, fmt.Errorf("the media of the message was removed")
+	default:
+		media, media_err := s.Store.GetMedia(ctx, *msg.MediaID)
+		if media_err != nil {
+			return SendResult{}, media_err
+		}
+
+		// every recipient and retry of the message shares the upload of the media
+		opts.MediaKey = strconv.FormatInt(media.ID, 10)
+		resp, err = waClient.SendMedia(msg.Recipient, whatsapp.MediaType(msg.Type), media.Data, media.MimeType, media.FileName, msg.Body, opts)
+	}
+	var limited *whatsapp.RateLimitError
+	if errors.As(err, &limited) {
+		return SendResult{}, &DeferredError{RetryAt: time.Now().Add(limited.RetryAfter), Reason: limited.Error()}
+	}
+	if err != nil {
+		return SendResult{}, err
+	}
+
+	return SendResult{
+		MessageID: resp.ID,
+		Timestamp: resp.Timestamp,
+	}, nil
+}
+
+// how long a receipt waits before it is tried again, the first receipt can arrive before the send is stored
+const receiptRetryDelay = 2 * time.Second
+
+// HandleReceipt is registered as whatsapp.EventHandler, the receipts of the recipients move the sent messages
+// of the session to delivered, read or played. Receipts of messages not sent through the outbox are ignored
+func (o *Outbox) HandleReceipt(evt whatsapp.Event) {
+	if evt.Type != whatsapp.EventReceipt {
+		return
+	}
+
+	receipt, ok := evt.Data.(whatsapp.ReceiptEvent)
+	if !ok || len(receipt.MessageIDs) == 0 || !slices.Contains(receipts, receipt.Receipt) {
+		return
+	}
+
+	go func() {
+		for try := 0; try < 2; try++ {
+			if try > 0 {
+				time.Sleep(receiptRetryDelay)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			n, err := o.store.MarkReceipt(ctx, evt.Session, receipt.MessageIDs, receipt.Receipt, receipt.Timestamp)
+			cancel()
+			if err != nil {
+				log.Printf("Outbox: failed to mark %s receipt of %v: %s\n", receipt.Receipt, receipt.MessageIDs, err.Error())
+				return
+			}
+
+			if n > 0 {
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,319 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/internal/notifier"
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/internal/subscriptions"
+	"github.com/robfig/cron/v3"
+)
+
+// how often a run with messages still in the outbox reloads their results, and how long it is followed
+// before it is closed with the results it has
+const (
+	runRefreshInterval = 30 * time.Second
+	runFollowTimeout   = 24 * time.Hour
+)
+
+// Scheduler fires the enabled schedules from postgres with a cron runner and logs every run.
+// The cron entries are rebuilt from the store with Reload after every change to the schedules
+type Scheduler struct {
+	store         *Store
+	notifier      *notifier.Notifier
+	subscriptions *subscriptions.Store
+	cron          *cron.Cron
+	stop          chan struct{}
+
+	mu      sync.Mutex
+	entries map[int64]cron.EntryID
+	running map[int64]bool // schedules with a run in progress, kept outside the cron entries so a reload keeps them
+}
+
+func New(store *Store, notifierService *notifier.Notifier, subscriptionStore *subscriptions.Store) *Scheduler {
+	return &Scheduler{
+		store:         store,
+		notifier:      notifierService,
+		subscriptions: subscriptionStore,
+		cron:          cron.New(),
+		stop:          make(chan struct{}),
+		entries:       map[int64]cron.EntryID{},
+		running:       map[int64]bool{},
+	}
+}
+
+func (s *Scheduler) Store() *Store {
+	return s.store
+}
+
+// Start loads the schedules and starts firing them
+func (s *Scheduler) Start(ctx context.Context) error {
+	if err := s.Reload(ctx); err != nil {
+		return err
+	}
+
+	// runs still sending when the server stopped are followed again
+	runs, err := s.store.sendingRuns(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load sending runs: %w", err)
+	}
+	for _, run := range runs {
+		go s.follow(run.ID, run.ScheduleID, run.Error, run.StartedAt, run.Results)
+	}
+
+	s.cron.Start()
+
+	return nil
+}
+
+// Stop stops firing schedules and waits for the running ones to finish
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	<-s.cron.Stop().Done()
+}
+
+// Reload replaces every cron entry with the enabled schedules from the store
+func (s *Scheduler) Reload(ctx context.Context) error {
+	schedules, err := s.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load schedules: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, entry_id := range s.entries {
+		s.cron.Remove(entry_id)
+		delete(s.entries, id)
+	}
+
+	for _, sch := range schedules {
+		if !sch.Enabled {
+			continue
+		}
+
+		schedule_id := sch.ID
+		entry_id, err := s.cron.AddFunc(sch.spec(), func() {
+			s.fire(schedule_id)
+		})
+		if err != nil {
+			// the schedule was validated on save, so this only happens when the tz database changed
+			log.Printf("Scheduler: skip schedule %d: %s\n", sch.ID, err.Error())
+			continue
+		}
+
+		s.entries[sch.ID] = entry_id
+	}
+
+	return nil
+}
+
+// NextRun fills NextRunAt of the schedule from its cron entry, left empty for disabled schedules
+func (s *Scheduler) NextRun(sch *Schedule) {
+	s.mu.Lock()
+	entry_id, ok := s.entries[sch.ID]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	entry := s.cron.Entry(entry_id)
+	if entry.Valid() && !entry.Next.IsZero() {
+		next := entry.Next
+		sch.NextRunAt = &next
+	}
+}
+
+// fire loads the latest version of the schedule and runs it, the result is kept in the run log
+func (s *Scheduler) fire(schedule_id int64) {
+	// a run that is slow, like one waiting for the llm, makes the next tick of its schedule skip instead of overlap
+	s.mu.Lock()
+	if s.running[schedule_id] {
+		s.mu.Unlock()
+		log.Printf("Scheduler: skip schedule %d, its last run is still going\n", schedule_id)
+		return
+	}
+	s.running[schedule_id] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, schedule_id)
+		s.mu.Unlock()
+	}()
+
+	ctx := context.Background()
+
+	sch, err := s.store.Get(ctx, schedule_id)
+	if err != nil {
+		log.Printf("Scheduler: failed to load schedule %d: %s\n", schedule_id, err.Error())
+		return
+	}
+
+	run_id, err := s.store.startRun(ctx, sch.ID)
+	if err != nil {
+		log.Printf("Scheduler: failed to log run of schedule %d: %s\n", sch.ID, err.Error())
+		return
+	}
+
+	results, err := s.run(ctx, sch)
+
+	run_err := ""
+	if err != nil {
+		run_err = err.Error()
+	}
+
+	status, sent := runStatus(results, err)
+	if err := s.store.saveRun(ctx, run_id, status, sent, len(results), run_err, results); err != nil {
+		log.Printf("Scheduler: failed to save run %d of schedule %d: %s\n", run_id, sch.ID, err.Error())
+	}
+
+	log.Printf("Scheduler: schedule %d (%s) finished with %s, %d of %d sent\n", sch.ID, sch.Name, status, sent, len(results))
+
+	// the first attempts are done but the outbox still has messages of the run, like the ones deferred by the rate limiter
+	if status == RunStatusSending {
+		go s.follow(run_id, sch.ID, run_err, time.Now(), results)
+	}
+}
+
+// follow reloads the results of a sending run from the outbox until every message is sent or failed
+func (s *Scheduler) follow(run_id, schedule_id int64, run_err string, started time.Time, results []models.WhatsappSendResult) {
+	ticker := time.NewTicker(runRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx := context.Background()
+		fresh, err := s.notifier.Results(ctx, results)
+		if err != nil {
+			log.Printf("Scheduler: failed to refresh run %d of schedule %d: %s\n", run_id, schedule_id, err.Error())
+			continue
+		}
+		results = fresh
+
+		status, sent := runStatus(results, nil)
+		if status == RunStatusSending && time.Since(started) > runFollowTimeout {
+			// close it with what was sent so far, the messages stay in the outbox
+			status = RunStatusPartial
+		}
+
+		if err := s.store.saveRun(ctx, run_id, status, sent, len(results), run_err, results); err != nil {
+			log.Printf("Scheduler: failed to save run %d of schedule %d: %s\n", run_id, schedule_id, err.Error())
+			continue
+		}
+
+		if status != RunStatusSending {
+			log.Printf("Scheduler: run %d of schedule %d finished with %s, %d of %d sent\n", run_id, schedule_id, status, sent, len(results))
+			return
+		}
+	}
+}
+
+// origin tells the history that the messages come from the schedule
+func origin(sch Schedule, broadcast string, using_llm bool) outbox.Origin {
+	return outbox.Origin{
+		Broadcast: broadcast,
+		Source:    outbox.SourceSchedule,
+		SourceRef: strconv.FormatInt(sch.ID, 10),
+		UsingLLM:  using_llm,
+		Typing:    sch.Typing,
+	}
+}
+
+// run builds the message for the job type of the schedule and sends it to its numbers and subscribers
+func (s *Scheduler) run(ctx context.Context, sch Schedule) ([]models.WhatsappSendResult, error) {
+	// schedules can not be fixed by the caller, so invalid numbers are always skipped and kept in the run log
+	numbers, invalid := []string{}, []models.WhatsappSendResult{}
+	if len(sch.WhatsappNumbers) > 0 {
+		numbers, invalid = s.notifier.CheckRecipients(sch.Session, sch.WhatsappNumbers)
+	}
+
+	var results []models.WhatsappSendResult
+	var err error
+
+	switch sch.JobType {
+	case JobTypeNews:
+		results, err = s.runNews(ctx, sch, numbers)
+	case JobTypeWeather:
+		results, err = s.runWeather(ctx, sch, numbers)
+	case JobTypeMessage:
+		if len(numbers) > 0 {
+			results, err = s.notifier.Send(ctx, sch.Session, origin(sch, outbox.BroadcastCustom, false), sch.Messages, numbers)
+		}
+	case JobTypeFeed:
+		results, err = s.runFeed(ctx, sch, numbers)
+	default:
+		err = fmt.Errorf("unknown job type %s", sch.JobType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return append(results, invalid...), nil
+}
+
+func (s *Scheduler) runNews(ctx context.Context, sch Schedule, numbers []string) ([]models.WhatsappSendResult, error) {
+	if sch.Subscribers {
+		subscribers, err := s.subscriptions.NewsNumbers(ctx, sch.Session, sch.Category)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subscribers: %w", err)
+		}
+		numbers = subscriptions.MergeNumbers(numbers, subscribers)
+	}
+
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+
+	payloads, err := s.notifier.BuildNewsPayloads(ctx, sch.NewsQuery(), sch.UsingLLM, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.notifier.SendPayloads(ctx, sch.Session, origin(sch, outbox.BroadcastNews, sch.UsingLLM), payloads, numbers)
+}
+
+func (s *Scheduler) runWeather(ctx context.Context, sch Schedule, numbers []string) ([]models.WhatsappSendResult, error) {
+	targets := []notifier.WeatherTarget{}
+	if len(numbers) > 0 {
+		targets = append(targets, notifier.WeatherTarget{Lat: sch.Lat, Lon: sch.Lon, Numbers: numbers})
+	}
+
+	if sch.Subscribers {
+		groups, err := s.subscriptions.WeatherGroups(ctx, sch.Session)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subscribers: %w", err)
+		}
+		targets = append(targets, notifier.SubscriberTargets(groups, numbers)...)
+	}
+
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	return s.notifier.SendWeather(ctx, sch.Session, origin(sch, outbox.BroadcastWeather, sch.UsingLLM), sch.WeatherType, targets, nil)
+}
+
+func (s *Scheduler) runFeed(ctx context.Context, sch Schedule, numbers []string) ([]models.WhatsappSendResult, error) {
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+
+	payloads, err := s.notifier.BuildFeedPayloads(ctx, sch.FeedURL, 0, sch.UsingLLM, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.notifier.SendPayloads(ctx, sch.Session, origin(sch, outbox.BroadcastNews, sch.UsingLLM), payloads, numbers)
+}
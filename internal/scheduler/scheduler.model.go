@@ -0,0 +1,197 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/pkg/feeds"
+	"github.com/momokii/go-wa-notifier/pkg/news"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	JobTypeNews    = "news"
+	JobTypeWeather = "weather"
+	JobTypeMessage = "message"
+	JobTypeFeed    = "feed"
+)
+
+const (
+	RunStatusRunning = "running" // building the message
+	RunStatusSending = "sending" // some messages are still queued, deferred or retrying in the outbox
+	RunStatusSuccess = "success" // every recipient got the message
+	RunStatusPartial = "partial" // some recipients failed, were invalid or were not sent within a day
+	RunStatusFailed  = "failed"  // building the message failed or no recipient got it
+	RunStatusSkipped = "skipped" // there was no one to send to, like a schedule for subscribers without any
+)
+
+// Schedule is a recurring broadcast, fired by its cron expression in its own timezone
+type Schedule struct {
+	ID              int64      `json:"id" example:"1"`
+	Name            string     `json:"name" example:"Morning tech news"`
+	Cron            string     `json:"cron" example:"0 7 * * *"`
+	Timezone        string     `json:"timezone" example:"Asia/Jakarta"`
+	JobType         string     `json:"job_type" example:"news"`                                             // options: news, weather, message, feed
+	Category        string     `json:"category,omitempty" example:"technology"`                             // news only
+	Country         string     `json:"country,omitempty" example:"us"`                                      // news only
+	Sources         string     `json:"sources,omitempty" example:"bbc-news,the-verge"`                      // news only, can not be mixed with country or category
+	FeedURL         string     `json:"feed_url,omitempty" example:"https://www.theverge.com/rss/index.xml"` // feed only, RSS 2.0 or Atom
+	WeatherType     string     `json:"weather_type,omitempty" example:"today"`                              // weather only, options: today, tomorrow
+	Lat             float64    `json:"lat,omitempty" example:"-6.2617"`                                     // weather only
+	Lon             float64    `json:"lon,omitempty" example:"106.8103"`                                    // weather only
+	Messages        string     `json:"messages,omitempty" example:"Hello there"`                            // message only
+	WhatsappNumbers []string   `json:"whatsapp_numbers" example:"6285727771234,6285667889887"`
+	Session         string     `json:"session" example:"default"`   // whatsapp session the schedule sends from
+	Subscribers     bool       `json:"subscribers" example:"false"` // news and weather only, also send to the subscribers of the session
+	UsingLLM        bool       `json:"using_llm" example:"true"`
+	Typing          bool       `json:"typing" example:"false"` // show typing in the chat before every message
+	Enabled         bool       `json:"enabled" example:"true"`
+	NextRunAt       *time.Time `json:"next_run_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Run is the log of one execution of a schedule
+type Run struct {
+	ID         int64                       `json:"id" example:"1"`
+	ScheduleID int64                       `json:"schedule_id" example:"1"`
+	Status     string                      `json:"status" example:"success"` // options: running, sending, success, partial, failed, skipped
+	Sent       int                         `json:"sent" example:"2"`
+	Total      int                         `json:"total" example:"2"`
+	Error      string                      `json:"error,omitempty"`
+	Results    []models.WhatsappSendResult `json:"results"`
+	StartedAt  time.Time                   `json:"started_at"`
+	FinishedAt *time.Time                  `json:"finished_at,omitempty"`
+}
+
+// pending tells if the outbox is still working on some messages of the results
+func pending(results []models.WhatsappSendResult) bool {
+	for _, result := range results {
+		switch result.Status {
+		case models.WhatsappSendStatusQueued, models.WhatsappSendStatusDeferred, models.WhatsappSendStatusRetrying:
+			return true
+		}
+	}
+
+	return false
+}
+
+// runStatus is the status of a run from the results of its messages so far and the error of building them
+func runStatus(results []models.WhatsappSendResult, err error) (status string, sent int) {
+	for _, result := range results {
+		if result.Status == models.WhatsappSendStatusSent {
+			sent++
+		}
+	}
+
+	switch {
+	case err != nil:
+		return RunStatusFailed, sent
+	case len(results) == 0:
+		return RunStatusSkipped, sent
+	case pending(results):
+		return RunStatusSending, sent
+	case sent == 0:
+		return RunStatusFailed, sent
+	case sent < len(results):
+		return RunStatusPartial, sent
+	default:
+		return RunStatusSuccess, sent
+	}
+}
+
+// NewsQuery is the headlines query of a news schedule
+func (s *Schedule) NewsQuery() news.Query {
+	return news.Query{
+		Category: s.Category,
+		Country:  s.Country,
+		Sources:  s.Sources,
+	}
+}
+
+// spec is the cron spec with the schedule timezone, understood by the cron parser
+func (s *Schedule) spec() string {
+	return "CRON_TZ=" + s.Timezone + " " + s.Cron
+}
+
+// Validate checks the cron expression, the timezone and the fields needed by the job type
+func (s *Schedule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+
+	if s.Session == "" {
+		s.Session = whatsapp.DefaultSession
+	}
+
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %s, use an IANA name like Asia/Jakarta", s.Timezone)
+	}
+
+	if _, err := cron.ParseStandard(s.spec()); err != nil {
+		return fmt.Errorf("invalid cron expression: %s", err.Error())
+	}
+
+	if s.Subscribers && (s.JobType == JobTypeMessage || s.JobType == JobTypeFeed) {
+		return fmt.Errorf("subscribers is only supported for news and weather schedules")
+	}
+
+	if len(s.WhatsappNumbers) == 0 && !s.Subscribers {
+		return fmt.Errorf("whatsapp numbers is required, or set subscribers to true")
+	}
+
+	if len(s.WhatsappNumbers) > 100 {
+		return fmt.Errorf("max whatsapp numbers is 100")
+	}
+
+	switch s.JobType {
+	case JobTypeNews:
+		if s.Category == "" && s.Country == "" && s.Sources == "" {
+			return fmt.Errorf("category, country or sources is required for news schedule")
+		}
+		if err := s.NewsQuery().Validate(); err != nil {
+			return err
+		}
+		// subscriptions are made per category
+		if s.Subscribers && s.Category == "" {
+			return fmt.Errorf("category is required to send to the subscribers")
+		}
+		if s.UsingLLM && s.Category != "" {
+			if _, err := utils.GetNewsType(s.Category); err != nil {
+				return fmt.Errorf("invalid category: %s", err.Error())
+			}
+		}
+	case JobTypeWeather:
+		if s.WeatherType != "today" && s.WeatherType != "tomorrow" {
+			return fmt.Errorf("weather_type is required and must be 'today' or 'tomorrow'")
+		}
+		if s.Lat < -90 || s.Lat > 90 {
+			return fmt.Errorf("latitude must be between -90 and 90")
+		}
+		if s.Lon < -180 || s.Lon > 180 {
+			return fmt.Errorf("longitude must be between -180 and 180")
+		}
+	case JobTypeMessage:
+		if s.Messages == "" {
+			return fmt.Errorf("messages is required for message schedule")
+		}
+	case JobTypeFeed:
+		if s.FeedURL == "" {
+			return fmt.Errorf("feed_url is required for feed schedule")
+		}
+		if err := feeds.ValidateURL(s.FeedURL); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("job_type must be one of news, weather, message, feed")
+	}
+
+	return nil
+}
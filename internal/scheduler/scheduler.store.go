@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/momokii/go-wa-notifier/internal/models"
+)
+
+var ErrNotFound = errors.New("schedule not found")
+
+const scheduleColumns = `id, name, cron, timezone, job_type, category, country, sources, feed_url, weather_type, lat, lon, messages,
+	whatsapp_numbers, session, subscribers, using_llm, typing, enabled, created_at, updated_at`
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Migrate creates the schedule and schedule run tables if they do not exist yet
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS wa_schedules (
+			id               BIGSERIAL PRIMARY KEY,
+			name             TEXT             NOT NULL,
+			cron             TEXT             NOT NULL,
+			timezone         TEXT             NOT NULL DEFAULT 'UTC',
+			job_type         TEXT             NOT NULL,
+			category         TEXT             NOT NULL DEFAULT '',
+			weather_type     TEXT             NOT NULL DEFAULT '',
+			lat              DOUBLE PRECISION NOT NULL DEFAULT 0,
+			lon              DOUBLE PRECISION NOT NULL DEFAULT 0,
+			messages         TEXT             NOT NULL DEFAULT '',
+			whatsapp_numbers TEXT[]           NOT NULL DEFAULT '{}',
+			using_llm        BOOLEAN          NOT NULL DEFAULT false,
+			enabled          BOOLEAN          NOT NULL DEFAULT true,
+			created_at       TIMESTAMPTZ      NOT NULL DEFAULT now(),
+			updated_at       TIMESTAMPTZ      NOT NULL DEFAULT now()
+		);
+		CREATE TABLE IF NOT EXISTS wa_schedule_runs (
+			id          BIGSERIAL PRIMARY KEY,
+			schedule_id BIGINT      NOT NULL REFERENCES wa_schedules (id) ON DELETE CASCADE,
+			status      TEXT        NOT NULL,
+			sent        INT         NOT NULL DEFAULT 0,
+			total       INT         NOT NULL DEFAULT 0,
+			error       TEXT        NOT NULL DEFAULT '',
+			results     JSONB       NOT NULL DEFAULT '[]',
+			started_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+			finished_at TIMESTAMPTZ
+		);
+		CREATE INDEX IF NOT EXISTS wa_schedule_runs_schedule_idx ON wa_schedule_runs (schedule_id, id);
+		ALTER TABLE wa_schedules ADD COLUMN IF NOT EXISTS session TEXT NOT NULL DEFAULT 'default';
+		ALTER TABLE wa_schedules ADD COLUMN IF NOT EXISTS subscribers BOOLEAN NOT NULL DEFAULT false;
+		ALTER TABLE wa_schedules ADD COLUMN IF NOT EXISTS typing BOOLEAN NOT NULL DEFAULT false;
+		ALTER TABLE wa_schedules ADD COLUMN IF NOT EXISTS feed_url TEXT NOT NULL DEFAULT '';
+		ALTER TABLE wa_schedules ADD COLUMN IF NOT EXISTS country TEXT NOT NULL DEFAULT '';
+		ALTER TABLE wa_schedules ADD COLUMN IF NOT EXISTS sources TEXT NOT NULL DEFAULT '';
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate schedule tables: %w", err)
+	}
+
+	return nil
+}
+
+func scanSchedule(row interface{ Scan(dest ...any) error }) (Schedule, error) {
+	var sch Schedule
+	err := row.Scan(
+		&sch.ID, &sch.Name, &sch.Cron, &sch.Timezone, &sch.JobType, &sch.Category, &sch.Country, &sch.Sources, &sch.FeedURL, &sch.WeatherType, &sch.Lat, &sch.Lon,
+		&sch.Messages, pq.Array(&sch.WhatsappNumbers), &sch.Session, &sch.Subscribers, &sch.UsingLLM, &sch.Typing, &sch.Enabled, &sch.CreatedAt, &sch.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Schedule{}, ErrNotFound
+	}
+
+	return sch, err
+}
+
+func (s *Store) List(ctx context.Context) ([]Schedule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+scheduleColumns+` FROM wa_schedules ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []Schedule{}
+	for rows.Next() {
+		sch, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, sch)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (s *Store) Get(ctx context.Context, id int64) (Schedule, error) {
+	return scanSchedule(s.db.QueryRowContext(ctx, `SELECT `+scheduleColumns+` FROM wa_schedules WHERE id = $1`, id))
+}
+
+func (s *Store) Create(ctx context.Context, sch Schedule) (Schedule, error) {
+	return scanSchedule(s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_schedules (name, cron, timezone, job_type, category, weather_type, lat, lon, messages,
+			whatsapp_numbers, session, subscribers, using_llm, typing, enabled, feed_url, country, sources)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING `+scheduleColumns,
+		sch.Name, sch.Cron, sch.Timezone, sch.JobType, sch.Category, sch.WeatherType, sch.Lat, sch.Lon, sch.Messages,
+		pq.Array(sch.WhatsappNumbers), sch.Session, sch.Subscribers, sch.UsingLLM, sch.Typing, sch.Enabled, sch.FeedURL, sch.Country, sch.Sources,
+	))
+}
+
+func (s *Store) Update(ctx context.Context, sch Schedule) (Schedule, error) {
+	return scanSchedule(s.db.QueryRowContext(ctx, `
+		UPDATE wa_schedules
+		SET name = $2, cron = $3, timezone = $4, job_type = $5, category = $6, weather_type = $7, lat = $8, lon = $9,
+			messages = $10, whatsapp_numbers = $11, session = $12, subscribers = $13, using_llm = $14, typing = $15, enabled = $16,
+			feed_url = $17, country = $18, sources = $19, updated_at = now()
+		WHERE id = $1
+		RETURNING `+scheduleColumns,
+		sch.ID, sch.Name, sch.Cron, sch.Timezone, sch.JobType, sch.Category, sch.WeatherType, sch.Lat, sch.Lon, sch.Messages,
+		pq.Array(sch.WhatsappNumbers), sch.Session, sch.Subscribers, sch.UsingLLM, sch.Typing, sch.Enabled, sch.FeedURL, sch.Country, sch.Sources,
+	))
+}
+
+func (s *Store) Delete(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM wa_schedules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *Store) startRun(ctx context.Context, schedule_id int64) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_schedule_runs (schedule_id, status) VALUES ($1, $2) RETURNING id`,
+		schedule_id, RunStatusRunning,
+	).Scan(&id)
+
+	return id, err
+}
+
+// saveRun stores the status and results of the run, finished_at is set once the status is final
+func (s *Store) saveRun(ctx context.Context, run_id int64, status string, sent, total int, run_err string, results []models.WhatsappSendResult) error {
+	if results == nil {
+		results = []models.WhatsappSendResult{}
+	}
+
+	results_json, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+
+	var finished_at *time.Time
+	if status != RunStatusRunning && status != RunStatusSending {
+		now := time.Now()
+		finished_at = &now
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE wa_schedule_runs
+		SET status = $2, sent = $3, total = $4, error = $5, results = $6, finished_at = $7
+		WHERE id = $1`, run_id, status, sent, total, run_err, results_json, finished_at)
+
+	return err
+}
+
+// sendingRuns returns the runs whose messages were still in the outbox when they were last saved
+func (s *Store) sendingRuns(ctx context.Context) ([]Run, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, schedule_id, status, sent, total, error, results, started_at, finished_at
+		FROM wa_schedule_runs
+		WHERE status = $1
+		ORDER BY id`, RunStatusSending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRuns(rows)
+}
+
+// ListRuns returns the latest runs of a schedule, newest first
+func (s *Store) ListRuns(ctx context.Context, schedule_id int64, limit, offset int) ([]Run, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, schedule_id, status, sent, total, error, results, started_at, finished_at
+		FROM wa_schedule_runs
+		WHERE schedule_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3`, schedule_id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRuns(rows)
+}
+
+func scanRuns(rows *sql.Rows) ([]Run, error) {
+	runs := []Run{}
+	for rows.Next() {
+		var run Run
+		var results_json []byte
+		if err := rows.Scan(&run.ID, &run.ScheduleID, &run.Status, &run.Sent, &run.Total, &run.Error, &results_json,
+			&run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(results_json, &run.Results); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
@@ -0,0 +1,70 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+// events that are pushed to the stream, messages and receipts are left to the webhooks
+var streamed = map[string]bool{
+	whatsapp.EventQR:           true,
+	whatsapp.EventPaired:       true,
+	whatsapp.EventConnected:    true,
+	whatsapp.EventDisconnected: true,
+	whatsapp.EventLoggedOut:    true,
+}
+
+// how many events a slow subscriber can fall behind before new events are dropped for it
+const subscriberBuffer = 16
+
+// Hub fans the connection events of the sessions out to the open event streams
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[chan whatsapp.Event]string
+}
+
+func New() *Hub {
+	return &Hub{
+		subscribers: map[chan whatsapp.Event]string{},
+	}
+}
+
+// Handle is registered as whatsapp.EventHandler, it never blocks the event loop,
+// a subscriber that is not reading misses the event
+func (h *Hub) Handle(evt whatsapp.Event) {
+	if !streamed[evt.Type] {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for events, session := range h.subscribers {
+		if session != evt.Session {
+			continue
+		}
+
+		select {
+		case events <- evt:
+		default:
+		}
+	}
+}
+
+// Subscribe returns the events of the session until unsubscribe is called
+func (h *Hub) Subscribe(session string) (<-chan whatsapp.Event, func()) {
+	events := make(chan whatsapp.Event, subscriberBuffer)
+
+	h.mu.Lock()
+	h.subscribers[events] = session
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		delete(h.subscribers, events)
+		h.mu.Unlock()
+	}
+
+	return events, unsubscribe
+}
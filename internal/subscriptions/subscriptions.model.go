@@ -0,0 +1,51 @@
+package subscriptions
+
+import "time"
+
+const (
+	TopicNews    = "news"
+	TopicWeather = "weather"
+)
+
+// Subscription is a number that asked to receive a broadcast topic from a session,
+// news subscriptions are per category and weather subscriptions use the last location shared by the number
+type Subscription struct {
+	ID        int64     `json:"id" example:"1"`
+	Session   string    `json:"session" example:"default"`
+	Number    string    `json:"number" example:"6285727771234"`
+	Topic     string    `json:"topic" example:"news"`                    // options: news, weather
+	Category  string    `json:"category,omitempty" example:"technology"` // news only
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Location is the last location a number shared with the bot
+type Location struct {
+	Number    string    `json:"number" example:"6285727771234"`
+	Lat       float64   `json:"lat" example:"-6.2617"`
+	Lon       float64   `json:"lon" example:"106.8103"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WeatherGroup is the weather subscribers of a session that share the same location
+type WeatherGroup struct {
+	Lat     float64
+	Lon     float64
+	Numbers []string
+}
+
+// MergeNumbers appends the extra numbers that are not in numbers yet
+func MergeNumbers(numbers, extra []string) []string {
+	seen := map[string]bool{}
+	for _, number := range numbers {
+		seen[number] = true
+	}
+
+	for _, number := range extra {
+		if !seen[number] {
+			seen[number] = true
+			numbers = append(numbers, number)
+		}
+	}
+
+	return numbers
+}
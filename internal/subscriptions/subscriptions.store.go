@@ -0,0 +1,189 @@
+package subscriptions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound   = errors.New("subscription not found")
+	ErrNoLocation = errors.New("no location shared yet")
+)
+
+const subscriptionColumns = `id, session, number, topic, category, created_at`
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Migrate creates the subscription and location tables if they do not exist yet
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS wa_subscriptions (
+			id         BIGSERIAL PRIMARY KEY,
+			session    TEXT        NOT NULL,
+			number     TEXT        NOT NULL,
+			topic      TEXT        NOT NULL,
+			category   TEXT        NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			UNIQUE (session, number, topic, category)
+		);
+		CREATE INDEX IF NOT EXISTS wa_subscriptions_topic_idx ON wa_subscriptions (session, topic, category);
+
+		CREATE TABLE IF NOT EXISTS wa_locations (
+			number     TEXT PRIMARY KEY,
+			lat        DOUBLE PRECISION NOT NULL,
+			lon        DOUBLE PRECISION NOT NULL,
+			updated_at TIMESTAMPTZ      NOT NULL DEFAULT now()
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate subscription tables: %w", err)
+	}
+
+	return nil
+}
+
+func scanSubscription(row interface{ Scan(dest ...any) error }) (Subscription, error) {
+	var sub Subscription
+	err := row.Scan(&sub.ID, &sub.Session, &sub.Number, &sub.Topic, &sub.Category, &sub.CreatedAt)
+	return sub, err
+}
+
+// Subscribe adds the subscription, subscribing twice to the same topic is not an error
+func (s *Store) Subscribe(ctx context.Context, session, number, topic, category string) (Subscription, error) {
+	return scanSubscription(s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_subscriptions (session, number, topic, category) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (session, number, topic, category) DO UPDATE SET topic = EXCLUDED.topic
+		RETURNING `+subscriptionColumns, session, number, topic, category))
+}
+
+// Unsubscribe removes the subscriptions of the number matching the topic and category,
+// an empty topic removes all of them and an empty category removes every category of the topic
+func (s *Store) Unsubscribe(ctx context.Context, session, number, topic, category string) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM wa_subscriptions
+		WHERE session = $1 AND number = $2 AND ($3 = '' OR topic = $3) AND ($4 = '' OR category = $4)`,
+		session, number, topic, category)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// List returns the subscriptions filtered by the non empty arguments
+func (s *Store) List(ctx context.Context, session, number, topic string) ([]Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+subscriptionColumns+` FROM wa_subscriptions
+		WHERE ($1 = '' OR session = $1) AND ($2 = '' OR number = $2) AND ($3 = '' OR topic = $3)
+		ORDER BY id`, session, number, topic)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []Subscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func (s *Store) Delete(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM wa_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// NewsNumbers returns the numbers subscribed to the news category on the session
+func (s *Store) NewsNumbers(ctx context.Context, session, category string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT number FROM wa_subscriptions
+		WHERE session = $1 AND topic = 'news' AND category = $2
+		ORDER BY id`, session, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	numbers := []string{}
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+
+	return numbers, rows.Err()
+}
+
+// WeatherGroups returns the weather subscribers of the session grouped by their location,
+// so the weather of every location is only built once. Subscribers without a location are left out
+func (s *Store) WeatherGroups(ctx context.Context, session string) ([]WeatherGroup, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT l.lat, l.lon, s.number FROM wa_subscriptions s
+		JOIN wa_locations l ON l.number = s.number
+		WHERE s.session = $1 AND s.topic = 'weather'
+		ORDER BY l.lat, l.lon, s.id`, session)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []WeatherGroup{}
+	for rows.Next() {
+		var lat, lon float64
+		var number string
+		if err := rows.Scan(&lat, &lon, &number); err != nil {
+			return nil, err
+		}
+
+		if n := len(groups); n > 0 && groups[n-1].Lat == lat && groups[n-1].Lon == lon {
+			groups[n-1].Numbers = append(groups[n-1].Numbers, number)
+			continue
+		}
+		groups = append(groups, WeatherGroup{Lat: lat, Lon: lon, Numbers: []string{number}})
+	}
+
+	return groups, rows.Err()
+}
+
+// SaveLocation keeps the last location shared by the number
+func (s *Store) SaveLocation(ctx context.Context, number string, lat, lon float64) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO wa_locations (number, lat, lon) VALUES ($1, $2, $3)
+		ON CONFLICT (number) DO UPDATE SET lat = EXCLUDED.lat, lon = EXCLUDED.lon, updated_at = now()`,
+		number, lat, lon)
+	return err
+}
+
+func (s *Store) GetLocation(ctx context.Context, number string) (Location, error) {
+	loc := Location{Number: number}
+	err := s.db.QueryRowContext(ctx, `SELECT lat, lon, updated_at FROM wa_locations WHERE number = $1`, number).
+		Scan(&loc.Lat, &loc.Lon, &loc.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Location{}, ErrNoLocation
+	}
+
+	return loc, err
+}
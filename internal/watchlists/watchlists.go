@@ -0,0 +1,244 @@
+package watchlists
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/internal/contacts"
+	"github.com/momokii/go-wa-notifier/internal/models"
+	"github.com/momokii/go-wa-notifier/internal/notifier"
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/internal/subscriptions"
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+)
+
+// how often the watcher looks for watchlists that are due
+const checkInterval = time.Minute
+
+// how far before the previous poll a poll searches, newsapi indexes some articles hours after they are published
+const pollOverlap = 24 * time.Hour
+
+// PollResult is the outcome of one poll of a watchlist
+type PollResult struct {
+	Found    int                         `json:"found" example:"10"`       // articles returned by the search
+	New      int                         `json:"new" example:"2"`          // articles the watchlist had not seen before
+	Baseline bool                        `json:"baseline" example:"false"` // first poll of the search, the articles are only remembered
+	Results  []models.WhatsappSendResult `json:"results"`
+}
+
+// Watcher polls the due watchlists in the background and sends an alert with the articles they had not seen yet.
+// Polls run one at a time, so an article is never alerted twice by overlapping polls
+type Watcher struct {
+	store    *Store
+	notifier *notifier.Notifier
+	contacts *contacts.Store
+
+	mu sync.Mutex
+}
+
+func New(store *Store, notifierService *notifier.Notifier, contactStore *contacts.Store) *Watcher {
+	return &Watcher{
+		store:    store,
+		notifier: notifierService,
+		contacts: contactStore,
+	}
+}
+
+func (w *Watcher) Store() *Store {
+	return w.store
+}
+
+// Start polls the due watchlists every checkInterval until the ctx is cancelled
+func (w *Watcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			w.pollDue(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (w *Watcher) pollDue(ctx context.Context) {
+	due, err := w.store.due(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Println("Watchlists: failed to get due watchlists: " + err.Error())
+		}
+		return
+	}
+
+	for _, wl := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		result, err := w.Poll(ctx, wl)
+		if err != nil {
+			log.Printf("Watchlists: poll of watchlist %d (%s) failed: %s\n", wl.ID, wl.Name, err.Error())
+			continue
+		}
+
+		if result.New > 0 && !result.Baseline {
+			log.Printf("Watchlists: watchlist %d (%s) alerted %d new articles\n", wl.ID, wl.Name, result.New)
+		}
+	}
+}
+
+// Poll searches the articles of the watchlist and alerts its recipients of the ones it has not seen yet.
+// The first poll of a search only remembers the articles. The next poll is planned one interval ahead either way
+func (w *Watcher) Poll(ctx context.Context, wl Watchlist) (PollResult, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result, err := w.poll(ctx, wl)
+
+	poll_err := ""
+	if err != nil {
+		poll_err = err.Error()
+	}
+
+	if err := w.store.markPolled(context.Background(), wl.ID, poll_err); err != nil {
+		log.Printf("Watchlists: failed to mark watchlist %d as polled: %s\n", wl.ID, err.Error())
+	}
+
+	return result, err
+}
+
+func (w *Watcher) poll(ctx context.Context, wl Watchlist) (PollResult, error) {
+	result := PollResult{Baseline: wl.LastPolledAt == nil}
+
+	query := wl.Search()
+	if wl.LastPolledAt != nil {
+		query.From = wl.LastPolledAt.Add(-pollOverlap).UTC().Format(time.RFC3339)
+	}
+
+	articles, err := w.notifier.SearchNews(query)
+	if err != nil {
+		return result, err
+	}
+	result.Found = len(articles)
+
+	fresh, err := w.unseen(ctx, wl.ID, articles)
+	if err != nil {
+		return result, fmt.Errorf("failed to check seen articles: %w", err)
+	}
+	result.New = len(fresh)
+
+	if len(fresh) == 0 || result.Baseline {
+		if err := w.store.addArticles(ctx, wl.ID, fresh, false); err != nil {
+			return result, fmt.Errorf("failed to save seen articles: %w", err)
+		}
+		return result, nil
+	}
+
+	// the articles are saved even when the alert fails, a message already in the outbox is retried there
+	// and sending it again on the next poll would alert the same articles twice
+	queued, send_err := w.alert(ctx, wl, fresh, &result)
+	if err := w.store.addArticles(ctx, wl.ID, fresh, queued); err != nil {
+		return result, fmt.Errorf("failed to save alerted articles: %w", err)
+	}
+
+	return result, send_err
+}
+
+// alert sends the new articles to the recipients of the watchlist and reports if any message reached the outbox
+func (w *Watcher) alert(ctx context.Context, wl Watchlist, fresh []newsapi.Article, result *PollResult) (bool, error) {
+	numbers, err := w.recipients(ctx, wl)
+	if err != nil {
+		return false, err
+	}
+
+	// invalid numbers can not be fixed by the poll, they are skipped and kept in the result like the schedules do
+	invalid := []models.WhatsappSendResult{}
+	if len(numbers) > 0 {
+		numbers, invalid = w.notifier.CheckRecipients(wl.Session, numbers)
+	}
+	defer func() {
+		result.Results = append(result.Results, invalid...)
+	}()
+
+	if len(numbers) == 0 {
+		return false, nil
+	}
+
+	origin := outbox.Origin{
+		Broadcast: outbox.BroadcastNews,
+		Source:    outbox.SourceWatchlist,
+		SourceRef: strconv.FormatInt(wl.ID, 10),
+		Typing:    wl.Typing,
+	}
+
+	result.Results, err = w.notifier.Send(ctx, wl.Session, origin, notifier.BuildNewsAlert(wl.Name, fresh), numbers)
+	if err != nil {
+		// only a failed enqueue leaves the outbox without the alert
+		return !errors.Is(err, notifier.ErrNotQueued), err
+	}
+
+	return true, nil
+}
+
+// unseen returns the articles the watchlist has not found before, in the order of the search.
+// Articles removed from newsapi come back titled [Removed] and are dropped
+func (w *Watcher) unseen(ctx context.Context, watchlist_id int64, articles []newsapi.Article) ([]newsapi.Article, error) {
+	urls := []string{}
+	for _, article := range articles {
+		if article.Url != "" {
+			urls = append(urls, article.Url)
+		}
+	}
+
+	if len(urls) == 0 {
+		return []newsapi.Article{}, nil
+	}
+
+	seen, err := w.store.seen(ctx, watchlist_id, urls)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := []newsapi.Article{}
+	for _, article := range articles {
+		if article.Url == "" || article.Title == "[Removed]" || seen[article.Url] {
+			continue
+		}
+
+		// newsapi can return the same url twice in one search
+		seen[article.Url] = true
+		fresh = append(fresh, article)
+	}
+
+	return fresh, nil
+}
+
+// recipients are the numbers of the watchlist and of the contacts with one of its tags at the time of the poll
+func (w *Watcher) recipients(ctx context.Context, wl Watchlist) ([]string, error) {
+	numbers := wl.WhatsappNumbers
+	if len(wl.Tags) == 0 {
+		return numbers, nil
+	}
+
+	tagged, err := w.contacts.Resolve(ctx, nil, wl.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contacts: %w", err)
+	}
+
+	contact_numbers := make([]string, 0, len(tagged))
+	for _, contact := range tagged {
+		contact_numbers = append(contact_numbers, contact.Number)
+	}
+
+	return subscriptions.MergeNumbers(numbers, contact_numbers), nil
+}
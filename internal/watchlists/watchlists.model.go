@@ -0,0 +1,107 @@
+package watchlists
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+// bounds of the polling interval, every poll is one NewsAPI request and the free plan allows 100 a day
+const (
+	MinIntervalMinutes     = 5
+	MaxIntervalMinutes     = 24 * 60
+	DefaultIntervalMinutes = 30
+)
+
+// Watchlist is a saved NewsAPI search that is polled on its interval, the articles it has not seen before are sent
+// to its recipients as an alert. The first poll only remembers the current articles, so a new watchlist does not
+// alert on old news
+type Watchlist struct {
+	ID              int64      `json:"id" example:"1"`
+	Name            string     `json:"name" example:"Acme brand mentions"`
+	Query           string     `json:"query" example:"\"Acme Corp\" OR Globex"`    // NewsAPI everything q, supports "exact match", AND, OR and NOT
+	SearchIn        string     `json:"search_in,omitempty" example:"title"`        // options: title, description, content, comma separated
+	Domains         string     `json:"domains,omitempty" example:"techcrunch.com"` // comma separated domains to restrict the search to
+	ExcludeDomains  string     `json:"exclude_domains,omitempty"`
+	Sources         string     `json:"sources,omitempty" example:"bbc-news"` // comma separated source ids to restrict the search to
+	Language        string     `json:"language,omitempty" example:"en"`
+	WhatsappNumbers []string   `json:"whatsapp_numbers" example:"6285727771234"`
+	Tags            []string   `json:"tags" example:"pr-team"` // contacts with one of the tags also get the alerts, resolved on every poll
+	Session         string     `json:"session" example:"default"`
+	IntervalMinutes int        `json:"interval_minutes" example:"30"`
+	Typing          bool       `json:"typing" example:"false"`
+	Enabled         bool       `json:"enabled" example:"true"`
+	LastPolledAt    *time.Time `json:"last_polled_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"` // error of the last poll, empty when it went fine
+	NextPollAt      time.Time  `json:"next_poll_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Article is an article found by a watchlist, alerted is false for the articles remembered by the first poll
+type Article struct {
+	URL         string     `json:"url" example:"https://techcrunch.com/2025/04/07/acme-raises"`
+	Title       string     `json:"title" example:"Acme raises its series B"`
+	Source      string     `json:"source" example:"TechCrunch"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Alerted     bool       `json:"alerted" example:"true"`
+	SeenAt      time.Time  `json:"seen_at"`
+}
+
+// Search is the NewsAPI everything request of the watchlist
+func (w *Watchlist) Search() newsapi.NewsAPIEverythingReq {
+	return newsapi.NewsAPIEverythingReq{
+		Q:              w.Query,
+		Searchin:       w.SearchIn,
+		Domains:        w.Domains,
+		ExcludeDomains: w.ExcludeDomains,
+		Sources:        w.Sources,
+		Language:       w.Language,
+		SortBy:         "publishedAt",
+	}
+}
+
+// Validate checks the search and the recipients and fills the defaults
+func (w *Watchlist) Validate() error {
+	w.Name = strings.TrimSpace(w.Name)
+	if w.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if err := w.Search().Validate(); err != nil {
+		return fmt.Errorf("invalid search: %s", err.Error())
+	}
+
+	if len(w.WhatsappNumbers) == 0 && len(w.Tags) == 0 {
+		return fmt.Errorf("whatsapp numbers or tags is required")
+	}
+
+	if len(w.WhatsappNumbers) > 100 {
+		return fmt.Errorf("max whatsapp numbers is 100")
+	}
+
+	tags := []string{}
+	for _, tag := range w.Tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	w.Tags = tags
+
+	if w.Session == "" {
+		w.Session = whatsapp.DefaultSession
+	}
+
+	if w.IntervalMinutes == 0 {
+		w.IntervalMinutes = DefaultIntervalMinutes
+	}
+
+	if w.IntervalMinutes < MinIntervalMinutes || w.IntervalMinutes > MaxIntervalMinutes {
+		return fmt.Errorf("interval_minutes must be between %d and %d", MinIntervalMinutes, MaxIntervalMinutes)
+	}
+
+	return nil
+}
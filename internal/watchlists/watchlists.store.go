@@ -0,0 +1,268 @@
+package watchlists
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+)
+
+var ErrNotFound = errors.New("watchlist not found")
+
+const watchlistColumns = `id, name, query, search_in, domains, exclude_domains, sources, language, whatsapp_numbers, tags, session,
+	interval_minutes, typing, enabled, last_polled_at, last_error, next_poll_at, created_at, updated_at`
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Migrate creates the watchlist and seen article tables if they do not exist yet
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS wa_watchlists (
+			id               BIGSERIAL PRIMARY KEY,
+			name             TEXT        NOT NULL,
+			query            TEXT        NOT NULL DEFAULT '',
+			search_in        TEXT        NOT NULL DEFAULT '',
+			domains          TEXT        NOT NULL DEFAULT '',
+			exclude_domains  TEXT        NOT NULL DEFAULT '',
+			language         TEXT        NOT NULL DEFAULT '',
+			whatsapp_numbers TEXT[]      NOT NULL DEFAULT '{}',
+			tags             TEXT[]      NOT NULL DEFAULT '{}',
+			session          TEXT        NOT NULL DEFAULT 'default',
+			interval_minutes INT         NOT NULL DEFAULT 30,
+			typing           BOOLEAN     NOT NULL DEFAULT false,
+			enabled          BOOLEAN     NOT NULL DEFAULT true,
+			last_polled_at   TIMESTAMPTZ,
+			last_error       TEXT        NOT NULL DEFAULT '',
+			next_poll_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+			created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS wa_watchlists_due_idx ON wa_watchlists (next_poll_at) WHERE enabled;
+		CREATE TABLE IF NOT EXISTS wa_watchlist_articles (
+			watchlist_id BIGINT      NOT NULL REFERENCES wa_watchlists (id) ON DELETE CASCADE,
+			url          TEXT        NOT NULL,
+			title        TEXT        NOT NULL DEFAULT '',
+			source       TEXT        NOT NULL DEFAULT '',
+			published_at TIMESTAMPTZ,
+			alerted      BOOLEAN     NOT NULL DEFAULT false,
+			seen_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (watchlist_id, url)
+		);
+		CREATE INDEX IF NOT EXISTS wa_watchlist_articles_seen_idx ON wa_watchlist_articles (watchlist_id, seen_at DESC);
+		ALTER TABLE wa_watchlists ADD COLUMN IF NOT EXISTS sources TEXT NOT NULL DEFAULT '';
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate watchlist tables: %w", err)
+	}
+
+	return nil
+}
+
+func scanWatchlist(row interface{ Scan(dest ...any) error }) (Watchlist, error) {
+	var w Watchlist
+	err := row.Scan(
+		&w.ID, &w.Name, &w.Query, &w.SearchIn, &w.Domains, &w.ExcludeDomains, &w.Sources, &w.Language, pq.Array(&w.WhatsappNumbers),
+		pq.Array(&w.Tags), &w.Session, &w.IntervalMinutes, &w.Typing, &w.Enabled, &w.LastPolledAt, &w.LastError, &w.NextPollAt,
+		&w.CreatedAt, &w.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Watchlist{}, ErrNotFound
+	}
+
+	if w.WhatsappNumbers == nil {
+		w.WhatsappNumbers = []string{}
+	}
+	if w.Tags == nil {
+		w.Tags = []string{}
+	}
+
+	return w, err
+}
+
+func scanWatchlists(rows *sql.Rows) ([]Watchlist, error) {
+	defer rows.Close()
+
+	watchlists := []Watchlist{}
+	for rows.Next() {
+		w, err := scanWatchlist(rows)
+		if err != nil {
+			return nil, err
+		}
+		watchlists = append(watchlists, w)
+	}
+
+	return watchlists, rows.Err()
+}
+
+func (s *Store) List(ctx context.Context) ([]Watchlist, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+watchlistColumns+` FROM wa_watchlists ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWatchlists(rows)
+}
+
+func (s *Store) Get(ctx context.Context, id int64) (Watchlist, error) {
+	return scanWatchlist(s.db.QueryRowContext(ctx, `SELECT `+watchlistColumns+` FROM wa_watchlists WHERE id = $1`, id))
+}
+
+// Create stores the watchlist, its first poll is due right away
+func (s *Store) Create(ctx context.Context, w Watchlist) (Watchlist, error) {
+	return scanWatchlist(s.db.QueryRowContext(ctx, `
+		INSERT INTO wa_watchlists (name, query, search_in, domains, exclude_domains, sources, language, whatsapp_numbers, tags, session,
+			interval_minutes, typing, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING `+watchlistColumns,
+		w.Name, w.Query, w.SearchIn, w.Domains, w.ExcludeDomains, w.Sources, w.Language, pq.Array(w.WhatsappNumbers), pq.Array(w.Tags), w.Session,
+		w.IntervalMinutes, w.Typing, w.Enabled,
+	))
+}
+
+// Update saves the watchlist. A changed search starts over with a baseline poll right away, so the articles of the
+// new search that were already published are not alerted. A shorter interval moves the next poll closer
+func (s *Store) Update(ctx context.Context, w Watchlist) (Watchlist, error) {
+	return scanWatchlist(s.db.QueryRowContext(ctx, `
+		WITH old AS (
+			SELECT (query, search_in, domains, exclude_domains, sources, language) IS DISTINCT FROM ($2, $3, $4, $5, $6, $7) AS search_changed
+			FROM wa_watchlists WHERE id = $1
+		)
+		UPDATE wa_watchlists
+		SET name = $8, query = $2, search_in = $3, domains = $4, exclude_domains = $5, sources = $6, language = $7,
+			whatsapp_numbers = $9, tags = $10, session = $11, interval_minutes = $12, typing = $13, enabled = $14,
+			last_polled_at = CASE WHEN old.search_changed THEN NULL ELSE last_polled_at END,
+			next_poll_at = CASE WHEN old.search_changed THEN now() ELSE LEAST(next_poll_at, now() + make_interval(mins => $12)) END,
+			updated_at = now()
+		FROM old
+		WHERE id = $1
+		RETURNING `+watchlistColumns,
+		w.ID, w.Query, w.SearchIn, w.Domains, w.ExcludeDomains, w.Sources, w.Language, w.Name,
+		pq.Array(w.WhatsappNumbers), pq.Array(w.Tags), w.Session, w.IntervalMinutes, w.Typing, w.Enabled,
+	))
+}
+
+func (s *Store) Delete(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM wa_watchlists WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// due returns the enabled watchlists whose next poll time has passed, the longest waiting first
+func (s *Store) due(ctx context.Context) ([]Watchlist, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+watchlistColumns+` FROM wa_watchlists
+		WHERE enabled AND next_poll_at <= now()
+		ORDER BY next_poll_at`)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWatchlists(rows)
+}
+
+// markPolled moves the next poll one interval ahead and keeps the error of the poll.
+// A failed poll leaves last_polled_at as is, so the next poll searches from the last successful one
+func (s *Store) markPolled(ctx context.Context, id int64, poll_err string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE wa_watchlists
+		SET last_polled_at = CASE WHEN $2 = '' THEN now() ELSE last_polled_at END,
+			last_error = $2,
+			next_poll_at = now() + make_interval(mins => interval_minutes)
+		WHERE id = $1`, id, poll_err)
+
+	return err
+}
+
+// seen returns which of the urls the watchlist already found before
+func (s *Store) seen(ctx context.Context, watchlist_id int64, urls []string) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT url FROM wa_watchlist_articles WHERE watchlist_id = $1 AND url = ANY($2)`, watchlist_id, pq.Array(urls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := map[string]bool{}
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		seen[url] = true
+	}
+
+	return seen, rows.Err()
+}
+
+// addArticles remembers the articles as seen by the watchlist, alerted tells if they were sent or only the baseline
+func (s *Store) addArticles(ctx context.Context, watchlist_id int64, articles []newsapi.Article, alerted bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO wa_watchlist_articles (watchlist_id, url, title, source, published_at, alerted)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (watchlist_id, url) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, article := range articles {
+		var published_at *time.Time
+		if t, err := time.Parse(time.RFC3339, article.PublishedAt); err == nil {
+			published_at = &t
+		}
+
+		if _, err := stmt.ExecContext(ctx, watchlist_id, article.Url, article.Title, article.Source.Name, published_at, alerted); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Articles returns the articles found by the watchlist, the latest first
+func (s *Store) Articles(ctx context.Context, watchlist_id int64, limit, offset int) ([]Article, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT url, title, source, published_at, alerted, seen_at
+		FROM wa_watchlist_articles
+		WHERE watchlist_id = $1
+		ORDER BY seen_at DESC, published_at DESC NULLS LAST
+		LIMIT $2 OFFSET $3`, watchlist_id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	articles := []Article{}
+	for rows.Next() {
+		var article Article
+		if err := rows.Scan(&article.URL, &article.Title, &article.Source, &article.PublishedAt, &article.Alerted, &article.SeenAt); err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+
+	return articles, rows.Err()
+}
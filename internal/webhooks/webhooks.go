@@ -0,0 +1,208 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
+)
+
+type delivery struct {
+	payload Payload
+	body    []byte
+	attempt int // attempts made so far
+}
+
+// endpoint is one webhook url with its own queue and workers, so a slow or dead url does not hold back the others
+type endpoint struct {
+	url   string
+	queue chan delivery
+}
+
+// Dispatcher posts the whatsapp events to the configured webhook urls, signed with HMAC-SHA256.
+// Failed deliveries are put back in the queue of their url after an exponential backoff, without holding a worker.
+// Events are kept in memory only so the ones still waiting are lost when the process stops
+type Dispatcher struct {
+	opts      Options
+	client    *http.Client
+	endpoints []*endpoint
+	wg        sync.WaitGroup
+}
+
+func New(opts Options) *Dispatcher {
+	defaults := DefaultOptions()
+	if opts.Workers < 1 {
+		opts.Workers = defaults.Workers
+	}
+	if opts.QueueSize < 1 {
+		opts.QueueSize = defaults.QueueSize
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = defaults.MaxAttempts
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaults.BaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaults.MaxBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaults.Timeout
+	}
+
+	endpoints := make([]*endpoint, 0, len(opts.URLs))
+	for _, url := range opts.URLs {
+		endpoints = append(endpoints, &endpoint{url: url, queue: make(chan delivery, opts.QueueSize)})
+	}
+
+	return &Dispatcher{
+		opts:      opts,
+		client:    &http.Client{Timeout: opts.Timeout},
+		endpoints: endpoints,
+	}
+}
+
+// Enabled reports if there is any url to post to
+func (d *Dispatcher) Enabled() bool {
+	return len(d.opts.URLs) > 0
+}
+
+// Start runs the delivery workers of every url until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	for _, ep := range d.endpoints {
+		for i := 0; i < d.opts.Workers; i++ {
+			d.wg.Add(1)
+			go func() {
+				defer d.wg.Done()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case item := <-ep.queue:
+						d.deliver(ctx, ep, item)
+					}
+				}
+			}()
+		}
+	}
+}
+
+// Wait blocks until all workers stopped
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Handle queues the event for delivery, it is registered as whatsapp.EventHandler and never blocks
+func (d *Dispatcher) Handle(evt whatsapp.Event) {
+	// whoever scans a QR code takes the session over, they never leave the server
+	if !d.Enabled() || evt.Type == whatsapp.EventQR {
+		return
+	}
+
+	payload := Payload{
+		ID:        uuid.NewString(),
+		Session:   evt.Session,
+		Type:      evt.Type,
+		Timestamp: evt.Timestamp,
+		Data:      evt.Data,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Webhooks: failed to encode %s event: %s\n", evt.Type, err.Error())
+		return
+	}
+
+	for _, ep := range d.endpoints {
+		d.enqueue(ep, delivery{payload: payload, body: body})
+	}
+}
+
+// enqueue puts the delivery in the queue of the url without blocking
+func (d *Dispatcher) enqueue(ep *endpoint, item delivery) {
+	select {
+	case ep.queue <- item:
+	default:
+		log.Printf("Webhooks: queue of %s is full, dropped %s event %s\n", ep.url, item.payload.Type, item.payload.ID)
+	}
+}
+
+// deliver makes one attempt to post the event to the url, a failed attempt is queued again after the backoff
+func (d *Dispatcher) deliver(ctx context.Context, ep *endpoint, item delivery) {
+	err := d.post(ctx, ep.url, item)
+	if err == nil {
+		return
+	}
+
+	item.attempt++
+	if item.attempt >= d.opts.MaxAttempts {
+		log.Printf("Webhooks: giving up %s event %s to %s after %d attempts: %s\n", item.payload.Type, item.payload.ID, ep.url, item.attempt, err.Error())
+		return
+	}
+
+	time.AfterFunc(d.backoff(item.attempt), func() {
+		if ctx.Err() == nil {
+			d.enqueue(ep, item)
+		}
+	})
+}
+
+// backoff is the wait before the next attempt, doubled after every failed attempt up to MaxBackoff
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	backoff := d.opts.BaseBackoff
+	for i := 1; i < attempt && backoff < d.opts.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, d.opts.MaxBackoff)
+}
+
+func (d *Dispatcher) post(ctx context.Context, url string, item delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(item.body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, item.payload.Type)
+	req.Header.Set(HeaderID, item.payload.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if d.opts.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(d.opts.Secret, timestamp, item.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>", receivers compute the same
+// with their copy of the secret to check the X-Webhook-Signature header
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,42 @@
+package webhooks
+
+import "time"
+
+// headers sent with every delivery
+const (
+	HeaderEvent     = "X-Webhook-Event"     // event type, like message or receipt
+	HeaderID        = "X-Webhook-Id"        // unique id of the delivery, the same for every retry
+	HeaderTimestamp = "X-Webhook-Timestamp" // unix seconds, part of the signed content
+	HeaderSignature = "X-Webhook-Signature" // sha256=<hex hmac of "<timestamp>.<body>" with the secret>
+)
+
+// Payload is the json body posted to the webhook urls
+type Payload struct {
+	ID        string    `json:"id"`
+	Session   string    `json:"session"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data,omitempty"`
+}
+
+type Options struct {
+	URLs        []string      // endpoints every event is posted to
+	Secret      string        // key of the HMAC signature, deliveries are not signed when empty
+	Workers     int           // number of concurrent deliveries per url
+	QueueSize   int           // events waiting for a worker of a url, new events for that url are dropped when it is full
+	MaxAttempts int           // attempts per url before the delivery is given up
+	BaseBackoff time.Duration // wait before the first retry, doubled on every next retry
+	MaxBackoff  time.Duration // upper bound of the wait between retries
+	Timeout     time.Duration // timeout of one http request
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Workers:     4,
+		QueueSize:   1000,
+		MaxAttempts: 5,
+		BaseBackoff: 2 * time.Second,
+		MaxBackoff:  2 * time.Minute,
+		Timeout:     10 * time.Second,
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gofiber/template/html/v2"
 	_ "github.com/joho/godotenv/autoload"
@@ -12,9 +14,26 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/helmet"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/momokii/go-wa-notifier/internal/auth"
+	"github.com/momokii/go-wa-notifier/internal/bot"
+	"github.com/momokii/go-wa-notifier/internal/contacts"
 	"github.com/momokii/go-wa-notifier/internal/handlers"
+	"github.com/momokii/go-wa-notifier/internal/jobs"
+	"github.com/momokii/go-wa-notifier/internal/notifier"
+	"github.com/momokii/go-wa-notifier/internal/outbox"
+	"github.com/momokii/go-wa-notifier/internal/scheduler"
+	"github.com/momokii/go-wa-notifier/internal/stream"
+	"github.com/momokii/go-wa-notifier/internal/subscriptions"
+	"github.com/momokii/go-wa-notifier/internal/watchlists"
+	"github.com/momokii/go-wa-notifier/internal/webhooks"
+	"github.com/momokii/go-wa-notifier/pkg/database"
+	"github.com/momokii/go-wa-notifier/pkg/news"
+	"github.com/momokii/go-wa-notifier/pkg/utils"
+	"github.com/momokii/go-wa-notifier/pkg/whatsapp"
 )
 
 // @title           Go Whatsapp Notifier API
@@ -32,7 +51,10 @@ import (
 // @BasePath  /api
 // @schemes   http
 
-// @securityDefinitions.basic  BasicAuth
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 API key with the scope of the route: send, status, logout or admin. "Authorization: Bearer <key>" works too
 
 // @externalDocs.description  OpenAPI
 // @externalDocs.url          https://swagger.io/resources/open-api/
@@ -61,21 +83,168 @@ func main() {
 		panic("API key is required")
 	}
 
+	// feeds that serve the headlines of their category while newsapi is rate limited or down
+	news_fallbacks := []news.NewsProvider{}
+	if fallback_feeds := utils.GetEnvList("NEWS_FALLBACK_FEEDS"); len(fallback_feeds) > 0 {
+		feedProvider, err := news.NewFeeds(fallback_feeds)
+		if err != nil {
+			panic(err.Error())
+		}
+		news_fallbacks = append(news_fallbacks, feedProvider)
+	}
+
 	openweather_api_key := os.Getenv("OPEN_WEATHER_API_KEY")
 	if openweather_api_key == "" {
 		panic("API key is required")
 	}
 
+	// database for the app tables, same postgres used by whatsmeow
+	db, err := database.NewPostgres()
+	if err != nil {
+		panic(err.Error())
+	}
+	defer db.Close()
+
+	// whatsapp sessions, every paired account is connected on start
+	waSessions, err := whatsapp.NewManager(db, whatsapp.LimitOptions{
+		PerMinute:         utils.GetEnvInt("WA_RATE_PER_MINUTE", 0),
+		Burst:             utils.GetEnvInt("WA_RATE_BURST", 0),
+		RecipientCooldown: utils.GetEnvDuration("WA_RECIPIENT_COOLDOWN", 0),
+		MinJitter:         utils.GetEnvDuration("WA_JITTER_MIN", 0),
+		MaxJitter:         utils.GetEnvDuration("WA_JITTER_MAX", 0),
+	})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	if err := waSessions.Migrate(context.Background()); err != nil {
+		panic(err.Error())
+	}
+
+	// inbound messages, receipts and connection changes are posted to the webhook urls
+	webhookDispatcher := webhooks.New(webhooks.Options{
+		URLs:        utils.GetEnvList("WEBHOOK_URLS"),
+		Secret:      os.Getenv("WEBHOOK_SECRET"),
+		MaxAttempts: utils.GetEnvInt("WEBHOOK_MAX_ATTEMPTS", 0),
+	})
+	if webhookDispatcher.Enabled() {
+		webhookDispatcher.Start(context.Background())
+		waSessions.AddEventHandler(webhookDispatcher.Handle)
+	}
+
+	// outbox for all outbound messages, sent by the workers in the background
+	outboxStore := outbox.NewStore(db)
+	if err := outboxStore.Migrate(context.Background()); err != nil {
+		panic(err.Error())
+	}
+
+	outboxQueue := outbox.New(outboxStore, outbox.WhatsappSender{Store: outboxStore, Sessions: waSessions}, outbox.Options{
+		Workers:        utils.GetEnvInt("OUTBOX_WORKERS", 0),
+		MaxAttempts:    utils.GetEnvInt("OUTBOX_MAX_ATTEMPTS", 0),
+		MediaRetention: utils.GetEnvDuration("OUTBOX_MEDIA_RETENTION", 0),
+	})
+	outboxQueue.Start(context.Background())
+
+	// receipts of the recipients move the sent messages to delivered, read or played
+	waSessions.AddEventHandler(outboxQueue.HandleReceipt)
+
+	// connection events of the sessions pushed to the dashboard and the api event streams
+	eventHub := stream.New()
+	waSessions.AddEventHandler(eventHub.Handle)
+
+	// notifier builds the news and weather broadcasts, jobs run them in the background for async requests
+	notifierService, err := notifier.New(news.NewNewsAPI(news_api_key), news_fallbacks, openweather_api_key, openaiClient, outboxQueue, waSessions)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	jobManager := jobs.NewManager(24 * time.Hour)
+
+	// subscriptions made by the recipients with the bot commands
+	subscriptionStore := subscriptions.NewStore(db)
+	if err := subscriptionStore.Migrate(context.Background()); err != nil {
+		panic(err.Error())
+	}
+
+	// contact directory, send requests can target contacts by id or tag
+	contactStore := contacts.NewStore(db)
+	if err := contactStore.Migrate(context.Background()); err != nil {
+		panic(err.Error())
+	}
+
+	if os.Getenv("BOT_ENABLED") != "false" {
+		commandBot := bot.New(subscriptionStore, notifierService, os.Getenv("BOT_USING_LLM") == "true", utils.GetEnvDuration("BOT_COOLDOWN", 0))
+		waSessions.AddEventHandler(commandBot.Handle)
+	}
+
+	// connect the sessions once every event handler is registered
+	if err := waSessions.Start(context.Background()); err != nil {
+		panic(err.Error())
+	}
+
+	// scheduler for the recurring broadcasts stored in postgres
+	scheduleStore := scheduler.NewStore(db)
+	if err := scheduleStore.Migrate(context.Background()); err != nil {
+		panic(err.Error())
+	}
+
+	broadcastScheduler := scheduler.New(scheduleStore, notifierService, subscriptionStore)
+	if err := broadcastScheduler.Start(context.Background()); err != nil {
+		panic(err.Error())
+	}
+	defer broadcastScheduler.Stop()
+
+	// keyword watchlists polled in the background, only new articles are alerted
+	watchlistStore := watchlists.NewStore(db)
+	if err := watchlistStore.Migrate(context.Background()); err != nil {
+		panic(err.Error())
+	}
+
+	watchlistWatcher := watchlists.New(watchlistStore, notifierService, contactStore)
+	watchlistWatcher.Start(context.Background())
+
+	// api keys with scopes, ADMIN_API_KEY is stored as an admin key so a fresh install can issue the other keys,
+	// without it the first start writes a random admin key to ADMIN_API_KEY_FILE
+	authStore := auth.NewStore(db)
+	if err := authStore.Migrate(context.Background()); err != nil {
+		panic(err.Error())
+	}
+
+	// session cookie login of the web dashboard for the admin user, a logged in admin can also call the api
+	webLogin, err := auth.NewWebLogin(os.Getenv("ADMIN_USERNAME"), os.Getenv("ADMIN_PASSWORD_HASH"), os.Getenv("COOKIE_SECURE") == "true")
+	if err != nil {
+		panic(err.Error())
+	}
+
+	apiAuth := auth.New(authStore, webLogin)
+	if err := apiAuth.Bootstrap(context.Background(), os.Getenv("ADMIN_API_KEY"), os.Getenv("ADMIN_API_KEY_FILE")); err != nil {
+		panic(err.Error())
+	}
+
 	// initiate handler
-	whatsAppHandler, err := handlers.NewWhatsappHandler(news_api_key, openweather_api_key, openaiClient)
+	whatsAppHandler, err := handlers.NewWhatsappHandler(notifierService, jobManager, waSessions, subscriptionStore, contactStore)
 	if err != nil {
 		panic(err.Error())
 	}
 
+	sessionsHandler := handlers.NewSessionsHandler(waSessions)
+	newsHandler := handlers.NewNewsHandler(notifierService)
+	eventsHandler := handlers.NewEventsHandler(eventHub, waSessions)
+	subscriptionsHandler := handlers.NewSubscriptionsHandler(subscriptionStore)
+	contactsHandler := handlers.NewContactsHandler(contactStore)
+	outboxHandler := handlers.NewOutboxHandler(outboxQueue)
+	messagesHandler := handlers.NewMessagesHandler(outboxStore)
+	jobsHandler := handlers.NewJobsHandler(jobManager, notifierService)
+	schedulesHandler := handlers.NewSchedulesHandler(broadcastScheduler)
+	watchlistsHandler := handlers.NewWatchlistsHandler(watchlistWatcher)
+	keysHandler := handlers.NewKeysHandler(apiAuth.Store())
+	webHandler := handlers.NewWebHandler(webLogin)
+
 	// FIBER app initiate
 	engine := html.New("./web", ".html")
 	app := fiber.New(fiber.Config{
-		Views: engine,
+		Views:     engine,
+		BodyLimit: 20 * 1024 * 1024, // room for media uploads on /api/wa/media
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
@@ -92,6 +261,7 @@ func main() {
 	api := app.Group("/api")
 
 	app.Use(cors.New())
+	app.Use(requestid.New()) // the request id is kept in the history of the messages the request sends
 	app.Use(logger.New())
 	app.Use(helmet.New())
 	if DEVMODE == "production" {
@@ -99,17 +269,78 @@ func main() {
 	}
 	app.Static("/web", "./web")
 
-	api.Get("/wa/status", whatsAppHandler.WAStatus)
-	api.Post("/wa/news", whatsAppHandler.SendNewsAPIWhatsapp)
-	api.Post("/wa/messages", whatsAppHandler.SendMessages)
-	api.Post("/wa/weathers", whatsAppHandler.SendWeatherAPIWhatsapp)
-	api.Post("/wa/logout", whatsAppHandler.WhatsAppLogout)
+	// every api route needs a key with the scope of the route, admin keys are allowed everywhere
+	requireSend := apiAuth.Require(auth.ScopeSend)
+	requireStatus := apiAuth.Require(auth.ScopeStatus)
+	requireLogout := apiAuth.Require(auth.ScopeLogout)
+	requireAdmin := apiAuth.Require(auth.ScopeAdmin)
 
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.Render("status-wa", fiber.Map{
-			"Title": "Go Whatsapp Notifier",
-		})
-	})
+	api.Get("/wa/status", requireStatus, whatsAppHandler.WAStatus)
+	api.Post("/wa/news", requireSend, whatsAppHandler.SendNewsAPIWhatsapp)
+	api.Post("/wa/news/search", requireSend, whatsAppHandler.SendNewsSearchWhatsapp)
+	api.Post("/wa/feeds", requireSend, whatsAppHandler.SendFeedWhatsapp)
+	api.Post("/wa/messages", requireSend, whatsAppHandler.SendMessages)
+	api.Post("/wa/weathers", requireSend, whatsAppHandler.SendWeatherAPIWhatsapp)
+	api.Post("/wa/media", requireSend, whatsAppHandler.SendMedia)
+	api.Post("/wa/logout", requireLogout, whatsAppHandler.WhatsAppLogout)
+	api.Post("/wa/pair", requireAdmin, whatsAppHandler.WhatsAppPairPhone)
+	api.Get("/wa/events", requireStatus, eventsHandler.StreamEvents)
+	api.Post("/wa/check-numbers", requireStatus, whatsAppHandler.WhatsAppCheckNumbers)
+	api.Get("/wa/groups", requireStatus, whatsAppHandler.WhatsAppGroups)
+	api.Post("/wa/groups/join", requireAdmin, whatsAppHandler.WhatsAppJoinGroup)
+
+	api.Get("/wa/sessions", requireStatus, sessionsHandler.ListSessions)
+	api.Post("/wa/sessions", requireAdmin, sessionsHandler.CreateSession)
+	api.Get("/wa/sessions/:name", requireStatus, sessionsHandler.GetSession)
+	api.Post("/wa/sessions/:name/pair", requireAdmin, sessionsHandler.PairSession)
+	api.Delete("/wa/sessions/:name", requireAdmin, sessionsHandler.DeleteSession)
+
+	api.Get("/news/sources", requireStatus, newsHandler.ListNewsSources)
+
+	api.Get("/jobs/:id", requireStatus, jobsHandler.GetJob)
+
+	api.Get("/schedules", requireStatus, schedulesHandler.ListSchedules)
+	api.Post("/schedules", requireAdmin, schedulesHandler.CreateSchedule)
+	api.Get("/schedules/:id", requireStatus, schedulesHandler.GetSchedule)
+	api.Put("/schedules/:id", requireAdmin, schedulesHandler.UpdateSchedule)
+	api.Delete("/schedules/:id", requireAdmin, schedulesHandler.DeleteSchedule)
+	api.Get("/schedules/:id/runs", requireStatus, schedulesHandler.ListScheduleRuns)
+
+	api.Get("/watchlists", requireStatus, watchlistsHandler.ListWatchlists)
+	api.Post("/watchlists", requireAdmin, watchlistsHandler.CreateWatchlist)
+	api.Get("/watchlists/:id", requireStatus, watchlistsHandler.GetWatchlist)
+	api.Put("/watchlists/:id", requireAdmin, watchlistsHandler.UpdateWatchlist)
+	api.Delete("/watchlists/:id", requireAdmin, watchlistsHandler.DeleteWatchlist)
+	api.Get("/watchlists/:id/articles", requireStatus, watchlistsHandler.ListWatchlistArticles)
+	api.Post("/watchlists/:id/poll", requireAdmin, watchlistsHandler.PollWatchlist)
+
+	api.Get("/subscriptions", requireStatus, subscriptionsHandler.ListSubscriptions)
+	api.Delete("/subscriptions/:id", requireAdmin, subscriptionsHandler.DeleteSubscription)
+
+	api.Get("/contacts", requireStatus, contactsHandler.ListContacts)
+	api.Post("/contacts", requireAdmin, contactsHandler.CreateContact)
+	api.Get("/contacts/:id", requireStatus, contactsHandler.GetContact)
+	api.Put("/contacts/:id", requireAdmin, contactsHandler.UpdateContact)
+	api.Delete("/contacts/:id", requireAdmin, contactsHandler.DeleteContact)
+
+	api.Get("/messages", requireStatus, messagesHandler.ListMessages)
+	api.Get("/messages/export", requireStatus, messagesHandler.ExportMessages)
+	api.Get("/messages/:id", requireStatus, messagesHandler.GetMessage)
+
+	api.Get("/keys", requireAdmin, keysHandler.ListKeys)
+	api.Post("/keys", requireAdmin, keysHandler.CreateKey)
+	api.Delete("/keys/:id", requireAdmin, keysHandler.RevokeKey)
+	api.Get("/audit", requireAdmin, keysHandler.ListAuditLog)
+
+	api.Get("/outbox", requireStatus, outboxHandler.ListOutbox)
+	api.Get("/outbox/:id", requireStatus, outboxHandler.GetOutboxMessage)
+	api.Post("/outbox/:id/replay", requireSend, outboxHandler.ReplayOutboxMessage)
+
+	// the dashboard shows the pairing QR code, so it is only for the logged in admin
+	app.Get("/login", webHandler.LoginPage)
+	app.Post("/login", limiter.New(limiter.Config{Max: 5, Expiration: time.Minute}), webHandler.Login)
+	app.Post("/logout", webHandler.Logout)
+	app.Get("/", webLogin.RequirePage(), webHandler.Dashboard)
 
 	if DEVMODE != "development" && DEVMODE != "production" {
 		log.Println("APP_ENV not set")
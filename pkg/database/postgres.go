@@ -0,0 +1,37 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "github.com/lib/pq" // Import PostgreSQL driver
+)
+
+// PostgresConnString builds the PostgreSQL connection string from the environment,
+// the same database is shared by whatsmeow session store and the app tables
+func PostgresConnString() string {
+	pgHost := os.Getenv("HOST_POSTGRES")
+	pgPort := os.Getenv("PORT_POSTGRES")
+	pgUser := os.Getenv("USER_POSTGRES")
+	pgPassword := os.Getenv("PASSWORD_POSTGRES")
+	pgDatabase := os.Getenv("DATABASE_POSTGRES")
+
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		pgHost, pgPort, pgUser, pgPassword, pgDatabase)
+}
+
+// NewPostgres opens and pings a connection pool to the PostgreSQL database
+func NewPostgres() (*sql.DB, error) {
+	db, err := sql.Open("postgres", PostgresConnString())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect postgres: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,354 @@
+package feeds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+	"github.com/momokii/go-wa-notifier/pkg/safehttp"
+	"golang.org/x/net/html/charset"
+)
+
+// MaxFeedSize is the biggest feed document Fetch reads
+const MaxFeedSize = 5 << 20
+
+// descriptions longer than this are cut, some feeds put the whole article in it
+const maxDescription = 300
+
+var ErrUnsupportedFeed = errors.New("unsupported feed, only RSS 2.0 and Atom are supported")
+
+// item dates seen in the wild, RSS should use RFC 822 and Atom RFC 3339 but many feeds do not
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	time.DateOnly,
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// ErrFetchFeed is returned for every feed that can not be downloaded, the reason is only logged
+// so the callers can not use the errors to probe the network of the server
+var ErrFetchFeed = errors.New("failed to fetch the feed")
+
+// the feed urls come from the api callers, so only public addresses are fetched
+var httpClient = safehttp.New(30 * time.Second)
+
+// ValidateURL checks the feed url is an absolute http or https url
+func ValidateURL(feed_url string) error {
+	if err := safehttp.ValidateURL(feed_url); err != nil {
+		return fmt.Errorf("invalid feed url: %s, use an http or https url", feed_url)
+	}
+
+	return nil
+}
+
+// Fetch downloads the feed from a public url and parses it
+func Fetch(feed_url string) (Feed, error) {
+	if err := ValidateURL(feed_url); err != nil {
+		return Feed{}, err
+	}
+
+	req, err := http.NewRequest("GET", feed_url, nil)
+	if err != nil {
+		return Feed{}, err
+	}
+
+	// some outlets block the default go user agent
+	req.Header.Set("User-Agent", "go-wa-notifier/1.0 (+https://kelanach.xyz)")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		log.Printf("Feeds: failed to fetch feed: %s\n", err.Error())
+		return Feed{}, ErrFetchFeed
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Feeds: failed to fetch feed %s: %s\n", resp.Request.URL.Redacted(), resp.Status)
+		return Feed{}, ErrFetchFeed
+	}
+
+	// read one byte more than allowed to know if the feed is too big
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxFeedSize+1))
+	if err != nil {
+		log.Printf("Feeds: failed to read feed %s: %s\n", resp.Request.URL.Redacted(), err.Error())
+		return Feed{}, ErrFetchFeed
+	}
+
+	if len(data) > MaxFeedSize {
+		return Feed{}, fmt.Errorf("feed is bigger than %d MB", MaxFeedSize>>20)
+	}
+
+	feed, err := Parse(data)
+	if err != nil {
+		return Feed{}, err
+	}
+
+	// relative links are resolved against the feed url
+	base := resp.Request.URL
+	feed.Link = resolveURL(base, feed.Link)
+	for i := range feed.Articles {
+		feed.Articles[i].Url = resolveURL(base, feed.Articles[i].Url)
+		feed.Articles[i].UrlToImage = resolveURL(base, feed.Articles[i].UrlToImage)
+	}
+
+	return feed, nil
+}
+
+// Parse reads an RSS 2.0 or Atom document, the articles are sorted newest first
+// and articles without a date are kept at the end in the order of the feed
+func Parse(data []byte) (Feed, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return Feed{}, err
+	}
+
+	var feed Feed
+	switch root.Local {
+	case "rss":
+		var doc rssFeed
+		if err := decode(data, &doc); err != nil {
+			return Feed{}, fmt.Errorf("invalid rss feed: %w", err)
+		}
+		feed = doc.feed()
+	case "feed":
+		var doc atomFeed
+		if err := decode(data, &doc); err != nil {
+			return Feed{}, fmt.Errorf("invalid atom feed: %w", err)
+		}
+		feed = doc.feed()
+	default:
+		return Feed{}, ErrUnsupportedFeed
+	}
+
+	slices.SortStableFunc(feed.Articles, func(a, b newsapi.Article) int {
+		// articles without a date that could be parsed have the zero time and go last
+		a_time, b_time := articleTime(a), articleTime(b)
+		switch {
+		case a_time.Equal(b_time):
+			return 0
+		case a_time.After(b_time):
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	return feed, nil
+}
+
+// decoder reads the xml in the charset of the document, many feeds are still in ISO-8859-1 or windows-1252
+func decoder(data []byte) *xml.Decoder {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.CharsetReader = charset.NewReaderLabel
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+
+	return d
+}
+
+func decode(data []byte, v any) error {
+	return decoder(data).Decode(v)
+}
+
+// rootElement returns the name of the first element of the document
+func rootElement(data []byte) (xml.Name, error) {
+	d := decoder(data)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return xml.Name{}, ErrUnsupportedFeed
+		}
+		if err != nil {
+			return xml.Name{}, fmt.Errorf("invalid feed: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+func (doc rssFeed) feed() Feed {
+	feed := Feed{
+		Title:    cleanText(doc.Channel.Title),
+		Link:     firstNonEmpty(doc.Channel.Links...),
+		Articles: make([]newsapi.Article, 0, len(doc.Channel.Items)),
+	}
+
+	for _, item := range doc.Channel.Items {
+		link := firstNonEmpty(item.Links...)
+		if link == "" && strings.HasPrefix(item.GUID, "http") {
+			link = strings.TrimSpace(item.GUID)
+		}
+
+		content := firstNonEmpty(item.Description, item.Content)
+		feed.Articles = append(feed.Articles, newsapi.Article{
+			Source:      newsapi.ArticleSource{Name: feed.Title},
+			Author:      cleanText(firstNonEmpty(item.Creator, item.Author)),
+			Title:       cleanText(item.Title),
+			Description: truncate(cleanText(content), maxDescription),
+			Url:         link,
+			UrlToImage:  imageOf(item.Enclosures, item.Media, item.Thumbnails, content),
+			PublishedAt: normalizeDate(firstNonEmpty(item.PubDate, item.Date)),
+			Content:     cleanText(firstNonEmpty(item.Content, item.Description)),
+		})
+	}
+
+	return feed
+}
+
+func (doc atomFeed) feed() Feed {
+	feed := Feed{
+		Title:    cleanText(doc.Title.String()),
+		Link:     alternateLink(doc.Links),
+		Articles: make([]newsapi.Article, 0, len(doc.Entries)),
+	}
+
+	for _, entry := range doc.Entries {
+		authors := []string{}
+		for _, author := range entry.Authors {
+			if name := cleanText(author.Name); name != "" {
+				authors = append(authors, name)
+			}
+		}
+
+		enclosures := []rssEnclosure{}
+		for _, link := range entry.Links {
+			if link.Rel == "enclosure" {
+				enclosures = append(enclosures, rssEnclosure{URL: link.Href, Type: link.Type})
+			}
+		}
+
+		summary, body := entry.Summary.String(), entry.Content.String()
+		content := firstNonEmpty(summary, body)
+		feed.Articles = append(feed.Articles, newsapi.Article{
+			Source:      newsapi.ArticleSource{Name: feed.Title},
+			Author:      strings.Join(authors, ", "),
+			Title:       cleanText(entry.Title.String()),
+			Description: truncate(cleanText(content), maxDescription),
+			Url:         alternateLink(entry.Links),
+			UrlToImage:  imageOf(enclosures, entry.Media, entry.Thumbnails, content),
+			PublishedAt: normalizeDate(firstNonEmpty(entry.Published, entry.Updated)),
+			Content:     cleanText(firstNonEmpty(body, summary)),
+		})
+	}
+
+	return feed
+}
+
+// alternateLink is the link to the page of the feed or entry, the first link without rel is alternate too
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+
+	return ""
+}
+
+var imagePattern = regexp.MustCompile(`<img[^>]+src=["']([^"']+)["']`)
+
+// imageOf picks the picture of the item from the enclosures, media rss or the first img of the html content
+func imageOf(enclosures []rssEnclosure, media, thumbnails []mediaContent, content string) string {
+	for _, enclosure := range enclosures {
+		if strings.HasPrefix(enclosure.Type, "image/") && enclosure.URL != "" {
+			return strings.TrimSpace(enclosure.URL)
+		}
+	}
+
+	for _, m := range media {
+		if (m.Medium == "image" || strings.HasPrefix(m.Type, "image/")) && m.URL != "" {
+			return strings.TrimSpace(m.URL)
+		}
+	}
+
+	for _, m := range thumbnails {
+		if m.URL != "" {
+			return strings.TrimSpace(m.URL)
+		}
+	}
+
+	if match := imagePattern.FindStringSubmatch(content); match != nil {
+		return html.UnescapeString(match[1])
+	}
+
+	return ""
+}
+
+// normalizeDate turns the date of the item into RFC 3339 in UTC like the newsapi dates,
+// a date in an unknown layout is kept as it is
+func normalizeDate(date string) string {
+	date = strings.TrimSpace(date)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+
+	return date
+}
+
+// articleTime is the parsed date of the article, zero when it has none or it could not be parsed
+func articleTime(article newsapi.Article) time.Time {
+	t, _ := time.Parse(time.RFC3339, article.PublishedAt)
+	return t
+}
+
+// cleanText strips the html of the text, unescapes its entities and collapses the whitespace
+func cleanText(text string) string {
+	text = tagPattern.ReplaceAllString(text, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+
+	return strings.TrimSpace(string(runes[:max])) + "…"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func resolveURL(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return u.String()
+}
@@ -0,0 +1,96 @@
+package feeds
+
+import (
+	"encoding/xml"
+
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+)
+
+// Feed is a parsed RSS 2.0 or Atom feed, the items are turned into newsapi articles so the news formatting
+// and summary prompts work the same for feeds
+type Feed struct {
+	Title    string            `json:"title" example:"The Verge"`
+	Link     string            `json:"link" example:"https://www.theverge.com"`
+	Articles []newsapi.Article `json:"articles"` // newest first
+}
+
+// link elements are lists because atom:link in an rss channel has the same local name as the rss link
+type rssFeed struct {
+	XMLName xml.Name `xml:"rss"`
+	Channel struct {
+		Title string    `xml:"title"`
+		Links []string  `xml:"link"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title       string         `xml:"title"`
+	Links       []string       `xml:"link"`
+	GUID        string         `xml:"guid"`
+	Description string         `xml:"description"`
+	Content     string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author      string         `xml:"author"`
+	Creator     string         `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string         `xml:"pubDate"`
+	Date        string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Enclosures  []rssEnclosure `xml:"enclosure"`
+	Media       []mediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnails  []mediaContent `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type rssEnclosure struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type mediaContent struct {
+	URL    string `xml:"url,attr"`
+	Medium string `xml:"medium,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   atomText    `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title      atomText       `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	ID         string         `xml:"id"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+	Authors    []atomPerson   `xml:"author"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Media      []mediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnails []mediaContent `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// atomText is a text construct of atom, xhtml text is a nested div so its markup is kept to be cleaned later
+type atomText struct {
+	Type  string `xml:"type,attr"` // text, html or xhtml
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return t.Inner
+	}
+
+	return t.Text
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"` // alternate when empty
+	Type string `xml:"type,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
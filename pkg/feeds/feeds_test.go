@@ -0,0 +1,155 @@
+package feeds
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func parseFixture(t *testing.T, name string) Feed {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+
+	feed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+
+	return feed
+}
+
+func TestParseRSS(t *testing.T) {
+	feed := parseFixture(t, "rss.xml")
+
+	if feed.Title != "Example News" {
+		t.Errorf("title = %q", feed.Title)
+	}
+	// the atom:link of the channel has the same local name and must not win over the rss link
+	if feed.Link != "https://news.example.com/" {
+		t.Errorf("link = %q", feed.Link)
+	}
+	if len(feed.Articles) != 3 {
+		t.Fatalf("got %d articles, want 3", len(feed.Articles))
+	}
+
+	newest := feed.Articles[0]
+	if newest.Title != "Newest story & more" {
+		t.Errorf("cdata title = %q", newest.Title)
+	}
+	if newest.Url != "https://news.example.com/newest" {
+		t.Errorf("guid permalink url = %q", newest.Url)
+	}
+	if newest.Description != "Rich HTML & entities" {
+		t.Errorf("cdata description = %q", newest.Description)
+	}
+	if newest.PublishedAt != "2025-06-03T08:30:00Z" {
+		t.Errorf("dc:date = %q", newest.PublishedAt)
+	}
+	if newest.Author != "Jane Writer" {
+		t.Errorf("dc:creator = %q", newest.Author)
+	}
+	if newest.UrlToImage != "https://cdn.example.com/newest.jpg" {
+		t.Errorf("media:content image = %q", newest.UrlToImage)
+	}
+	if newest.Source.Name != "Example News" {
+		t.Errorf("source = %q", newest.Source.Name)
+	}
+
+	older := feed.Articles[1]
+	if older.Url != "https://news.example.com/older" || older.PublishedAt != "2025-06-02T08:00:00Z" {
+		t.Errorf("older article = %q at %q", older.Url, older.PublishedAt)
+	}
+	if older.UrlToImage != "https://cdn.example.com/older.jpg" {
+		t.Errorf("enclosure image = %q", older.UrlToImage)
+	}
+
+	// articles without a date go last
+	if undated := feed.Articles[2]; undated.Title != "Story without a date" || undated.PublishedAt != "" {
+		t.Errorf("last article = %q at %q", undated.Title, undated.PublishedAt)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	feed := parseFixture(t, "atom.xml")
+
+	if feed.Title != "Example Blog" {
+		t.Errorf("title = %q", feed.Title)
+	}
+	if feed.Link != "https://blog.example.com/" {
+		t.Errorf("alternate link = %q, rel self must be skipped", feed.Link)
+	}
+	if len(feed.Articles) != 2 {
+		t.Fatalf("got %d articles, want 2", len(feed.Articles))
+	}
+
+	// published wins over updated, so the entry updated later is still the older one
+	updated, published := feed.Articles[0], feed.Articles[1]
+
+	if updated.Url != "https://blog.example.com/posts/updated" {
+		t.Errorf("link without rel = %q", updated.Url)
+	}
+	if updated.PublishedAt != "2025-06-02T09:00:00Z" {
+		t.Errorf("updated date = %q", updated.PublishedAt)
+	}
+	if updated.Description != "Escaped summary" {
+		t.Errorf("html summary = %q", updated.Description)
+	}
+
+	if published.Url != "https://blog.example.com/posts/published" {
+		t.Errorf("alternate url = %q", published.Url)
+	}
+	if published.PublishedAt != "2025-06-01T09:00:00Z" {
+		t.Errorf("published date = %q", published.PublishedAt)
+	}
+	if published.Content != "Hello xhtml & world" || published.Description != "Hello xhtml & world" {
+		t.Errorf("xhtml content = %q, description = %q", published.Content, published.Description)
+	}
+	if published.Author != "Ann, Bob" {
+		t.Errorf("authors = %q", published.Author)
+	}
+}
+
+func TestParseLatin1(t *testing.T) {
+	feed := parseFixture(t, "latin1.xml")
+
+	if len(feed.Articles) != 1 {
+		t.Fatalf("got %d articles, want 1", len(feed.Articles))
+	}
+
+	article := feed.Articles[0]
+	if article.Title != "Café à Paris" || article.Description != "Été très chaud" {
+		t.Errorf("decoded text = %q, %q", article.Title, article.Description)
+	}
+	if article.PublishedAt != "2025-06-03T07:00:00Z" {
+		t.Errorf("date = %q", article.PublishedAt)
+	}
+}
+
+func TestParseUnsupported(t *testing.T) {
+	for _, doc := range []string{`<html><body>not a feed</body></html>`, ``} {
+		if _, err := Parse([]byte(doc)); !errors.Is(err, ErrUnsupportedFeed) {
+			t.Errorf("Parse(%q) error = %v, want ErrUnsupportedFeed", doc, err)
+		}
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	base, _ := url.Parse("https://journal.example.fr/rss/feed.xml")
+
+	for ref, want := range map[string]string{
+		"/articles/cafe":                "https://journal.example.fr/articles/cafe",
+		"image.jpg":                     "https://journal.example.fr/rss/image.jpg",
+		"https://cdn.example.com/a.jpg": "https://cdn.example.com/a.jpg",
+		"":                              "",
+	} {
+		if got := resolveURL(base, ref); got != want {
+			t.Errorf("resolveURL(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package news
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/momokii/go-wa-notifier/pkg/feeds"
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+)
+
+// Feeds serves the headlines of a category from an RSS or Atom feed, it is meant as a fallback of the news apis.
+// The keywords of the query are matched against the title and description of the items
+type Feeds struct {
+	urls map[string]string // feed url by category
+}
+
+// NewFeeds takes the feeds as category=url items, like general=https://feeds.bbci.co.uk/news/rss.xml
+func NewFeeds(items []string) (*Feeds, error) {
+	urls := map[string]string{}
+	for _, item := range items {
+		category, feed_url, ok := strings.Cut(item, "=")
+		category, feed_url = strings.TrimSpace(category), strings.TrimSpace(feed_url)
+		if !ok || category == "" {
+			return nil, fmt.Errorf("invalid news feed: %s, use category=url", item)
+		}
+
+		if err := (Query{Category: category}).Validate(); err != nil {
+			return nil, err
+		}
+
+		if err := feeds.ValidateURL(feed_url); err != nil {
+			return nil, err
+		}
+
+		urls[category] = feed_url
+	}
+
+	return &Feeds{urls: urls}, nil
+}
+
+func (p *Feeds) Name() string {
+	return "RSS"
+}
+
+func (p *Feeds) TopHeadlines(query Query) ([]newsapi.Article, error) {
+	if query.Country != "" || query.Sources != "" {
+		return nil, fmt.Errorf("%w: feeds can not filter by country or sources", ErrUnsupported)
+	}
+
+	category := query.Category
+	if category == "" {
+		category = "general"
+	}
+
+	feed_url, ok := p.urls[category]
+	if !ok {
+		return nil, fmt.Errorf("%w: no feed for the %s category", ErrUnsupported, category)
+	}
+
+	feed, err := feeds.Fetch(feed_url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+
+	keywords := strings.Fields(strings.ToLower(query.Q))
+	articles := []newsapi.Article{}
+	for _, article := range feed.Articles {
+		if query.Limit > 0 && len(articles) == query.Limit {
+			break
+		}
+
+		text := strings.ToLower(article.Title + " " + article.Description)
+		if containsAll(text, keywords) {
+			articles = append(articles, article)
+		}
+	}
+
+	return articles, nil
+}
+
+func containsAll(text string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if !strings.Contains(text, keyword) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,62 @@
+package news
+
+import (
+	"errors"
+	"log"
+
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+)
+
+var (
+	// ErrUnavailable is wrapped by errors of a provider that can not answer right now, like a rate limit or a server error
+	ErrUnavailable = errors.New("news provider unavailable")
+	// ErrUnsupported is wrapped by errors of a provider that can not serve the filters of the query
+	ErrUnsupported = errors.New("query not supported by the news provider")
+)
+
+// NewsProvider gets the top headlines from a news api or feed. The articles use the newsapi article
+// so every provider is formatted and summarized the same way
+type NewsProvider interface {
+	Name() string // shown in the footer of the news messages
+	TopHeadlines(query Query) ([]newsapi.Article, error)
+}
+
+// Failover asks its providers in order and moves on to the next one when a provider is unavailable
+// or does not support the query, any other error is returned right away
+type Failover struct {
+	providers []NewsProvider
+}
+
+func NewFailover(providers ...NewsProvider) *Failover {
+	return &Failover{providers: providers}
+}
+
+// TopHeadlines returns the headlines of the first provider that answers with the name of that provider
+func (f *Failover) TopHeadlines(query Query) ([]newsapi.Article, string, error) {
+	var last_err error
+	for _, provider := range f.providers {
+		articles, err := provider.TopHeadlines(query)
+		if err == nil {
+			return articles, provider.Name(), nil
+		}
+
+		if !errors.Is(err, ErrUnavailable) && !errors.Is(err, ErrUnsupported) {
+			return nil, "", err
+		}
+
+		// an unsupported query says less about what went wrong than an outage, so it does not hide one
+		if last_err == nil || errors.Is(err, ErrUnavailable) {
+			last_err = err
+		}
+
+		if errors.Is(err, ErrUnavailable) {
+			log.Printf("News: %s failed, trying the next provider: %s\n", provider.Name(), err.Error())
+		}
+	}
+
+	if last_err == nil {
+		return nil, "", errors.New("no news provider is configured")
+	}
+
+	return nil, "", last_err
+}
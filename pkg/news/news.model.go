@@ -0,0 +1,29 @@
+package news
+
+import "github.com/momokii/go-wa-notifier/pkg/newsapi"
+
+// Query asks a provider for the top headlines. The category and country use the NewsAPI options,
+// a provider that can not serve a filter answers with ErrUnsupported
+type Query struct {
+	Category string // options: business, entertainment, general, health, science, sports, technology
+	Country  string // ISO 3166-1 code of the country, like us or id
+	Sources  string // comma separated NewsAPI source ids, can not be mixed with country or category
+	Q        string // keywords to look for in the headlines
+	Limit    int    // most articles to return, the provider default when 0
+}
+
+// Validate checks the category, country and sources against the NewsAPI options, every provider takes the same ones
+func (query Query) Validate() error {
+	return query.topHeadlinesReq().Validate()
+}
+
+func (query Query) topHeadlinesReq() newsapi.NewsAPITopHeadlinesReq {
+	return newsapi.NewsAPITopHeadlinesReq{
+		Category: query.Category,
+		Country:  query.Country,
+		Sources:  query.Sources,
+		Q:        query.Q,
+		PageSize: query.Limit,
+		Page:     1,
+	}
+}
@@ -0,0 +1,89 @@
+package news
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/momokii/go-wa-notifier/pkg/newsapi"
+)
+
+// NewsAPI is the newsapi.org provider. Besides the headlines it has the keyword search and the source list,
+// which the other providers do not have
+type NewsAPI struct {
+	api_key string
+}
+
+func NewNewsAPI(api_key string) *NewsAPI {
+	return &NewsAPI{api_key: api_key}
+}
+
+func (p *NewsAPI) Name() string {
+	return "NewsAPI"
+}
+
+func (p *NewsAPI) TopHeadlines(query Query) ([]newsapi.Article, error) {
+	// a bad query is the caller's fault, checked first so only the errors of the call itself are ErrUnavailable
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	news_resp, err := newsapi.NewsAPITopHeadlines(p.api_key, query.topHeadlinesReq())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+
+	if err := responseError(news_resp.Status, news_resp.Code, news_resp.Message, news_resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	return news_resp.Articles, nil
+}
+
+// Search searches every article of newsapi with the keywords, domains or sources of the query
+func (p *NewsAPI) Search(query newsapi.NewsAPIEverythingReq) ([]newsapi.Article, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	news_resp, err := newsapi.NewsAPIEverything(p.api_key, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+
+	if err := responseError(news_resp.Status, news_resp.Code, news_resp.Message, news_resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	return news_resp.Articles, nil
+}
+
+// Sources lists the newsapi sources matching the filters
+func (p *NewsAPI) Sources(query newsapi.NewsAPISourcesReq) ([]newsapi.Source, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	sources_resp, err := newsapi.NewsAPISources(p.api_key, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err.Error())
+	}
+
+	if err := responseError(sources_resp.Status, sources_resp.Code, sources_resp.Message, sources_resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	return sources_resp.Sources, nil
+}
+
+// responseError turns an error answer of newsapi into an error, rate limits and server errors are ErrUnavailable
+func responseError(status, code, message string, status_code int) error {
+	if status == "ok" {
+		return nil
+	}
+
+	if code == "rateLimited" || status_code >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: newsapi: %s", ErrUnavailable, message)
+	}
+
+	return fmt.Errorf("failed to get news from newsapi: %s", message)
+}
@@ -5,18 +5,80 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// validateSources checks the comma separated source ids, NewsAPI takes 20 at most
+func validateSources(sources string) error {
+	count := 0
+	for _, source := range strings.Split(sources, ",") {
+		if strings.TrimSpace(source) != "" {
+			count++
+		}
+	}
+
+	if count > maxSources {
+		return fmt.Errorf("too many sources: %d. max sources is %d", count, maxSources)
+	}
+
+	return nil
+}
+
+// Validate checks the search parameters before the request is made, NewsAPI needs a query, domains or sources
+func (query_req NewsAPIEverythingReq) Validate() error {
+	if strings.TrimSpace(query_req.Q) == "" && strings.TrimSpace(query_req.Domains) == "" && strings.TrimSpace(query_req.Sources) == "" {
+		return fmt.Errorf("q, domains or sources is required")
+	}
+
+	if err := validateSources(query_req.Sources); err != nil {
+		return err
+	}
+
+	if query_req.SortBy != "" && !slices.Contains(sortByOptions, query_req.SortBy) {
+		return fmt.Errorf("invalid sortBy: %s. valid sortBy are %s", query_req.SortBy, strings.Join(sortByOptions, ", "))
+	}
+
+	if query_req.Language != "" && !slices.Contains(languageOptions, query_req.Language) {
+		return fmt.Errorf("invalid language: %s. valid languages are %s", query_req.Language, strings.Join(languageOptions, ", "))
+	}
+
+	for _, search_in := range strings.Split(query_req.Searchin, ",") {
+		if search_in = strings.TrimSpace(search_in); search_in != "" && !slices.Contains(searchInOptions, search_in) {
+			return fmt.Errorf("invalid searchIn: %s. valid searchIn are %s", search_in, strings.Join(searchInOptions, ", "))
+		}
+	}
+
+	for name, date := range map[string]string{"from": query_req.From, "to": query_req.To} {
+		if date == "" {
+			continue
+		}
+
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			if _, err := time.Parse(time.RFC3339, date); err != nil {
+				return fmt.Errorf("invalid %s: %s. use YYYY-MM-DD or YYYY-MM-DDTHH:MM:SSZ", name, date)
+			}
+		}
+	}
+
+	return nil
+}
+
 func NewsAPIEverything(api_key string, query_req NewsAPIEverythingReq) (NewsAPIResponse, error) {
 
 	if api_key == "" {
 		return NewsAPIResponse{}, nil
 	}
 
+	if err := query_req.Validate(); err != nil {
+		return NewsAPIResponse{}, err
+	}
+
 	// * make a request to the NewsAPI with the given parameters
 	httpClient := &http.Client{}
-	req, err := http.NewRequest("GET", NewsAPIURLTopHeadlines, nil)
+	req, err := http.NewRequest("GET", NewsAPIURLEverything, nil)
 	if err != nil {
 		return NewsAPIResponse{}, err
 	}
@@ -53,11 +115,6 @@ func NewsAPIEverything(api_key string, query_req NewsAPIEverythingReq) (NewsAPIR
 
 	// set sortBy parameter
 	if query_req.SortBy != "" {
-
-		if query_req.SortBy != "relevancy" && query_req.SortBy != "popularity" && query_req.SortBy != "publishedAt" {
-			return NewsAPIResponse{}, fmt.Errorf("invalid sortBy: %s. valid sortBy are relevancy, popularity, publishedAt", query_req.SortBy)
-		}
-
 		q.Set("sortBy", query_req.SortBy)
 	}
 
@@ -91,6 +148,11 @@ func NewsAPIEverything(api_key string, query_req NewsAPIEverythingReq) (NewsAPIR
 		q.Set("searchIn", query_req.Searchin)
 	}
 
+	// set sources parameter
+	if query_req.Sources != "" {
+		q.Set("sources", query_req.Sources)
+	}
+
 	// Assign the modified query values back to the request URL
 	req.URL.RawQuery = q.Encode()
 
@@ -111,18 +173,44 @@ func NewsAPIEverything(api_key string, query_req NewsAPIEverythingReq) (NewsAPIR
 	// decode response from json to struct
 	var newsAPIResponse NewsAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&newsAPIResponse); err != nil {
+		// errors of an outage or a proxy may not be json, keep the status so the caller can tell them apart
+		if resp.StatusCode != http.StatusOK {
+			return NewsAPIResponse{Status: "error", Message: resp.Status, StatusCode: resp.StatusCode}, nil
+		}
 		return NewsAPIResponse{}, err
 	}
+	newsAPIResponse.StatusCode = resp.StatusCode
 
 	return newsAPIResponse, nil
 }
 
+// Validate checks the headline parameters before the request is made, sources can not be mixed with country or category
+func (query_req NewsAPITopHeadlinesReq) Validate() error {
+	if query_req.Category != "" && !slices.Contains(categoryOptions, query_req.Category) {
+		return fmt.Errorf("invalid category: %s. valid categories are %s", query_req.Category, strings.Join(categoryOptions, ", "))
+	}
+
+	if query_req.Country != "" && !slices.Contains(countryOptions, query_req.Country) {
+		return fmt.Errorf("invalid country code: %s. valid country codes are %s", query_req.Country, strings.Join(countryOptions, ", "))
+	}
+
+	if strings.TrimSpace(query_req.Sources) != "" && (query_req.Country != "" || query_req.Category != "") {
+		return fmt.Errorf("sources can not be mixed with country or category")
+	}
+
+	return validateSources(query_req.Sources)
+}
+
 func NewsAPITopHeadlines(api_key string, query_req NewsAPITopHeadlinesReq) (NewsAPIResponse, error) {
 
 	if api_key == "" {
 		return NewsAPIResponse{}, nil
 	}
 
+	if err := query_req.Validate(); err != nil {
+		return NewsAPIResponse{}, err
+	}
+
 	// * make a request to the NewsAPI with the given parameters
 	httpClient := &http.Client{}
 	req, err := http.NewRequest("GET", NewsAPIURLTopHeadlines, nil)
@@ -154,21 +242,17 @@ func NewsAPITopHeadlines(api_key string, query_req NewsAPITopHeadlinesReq) (News
 	q.Set("page", strconv.Itoa(page))
 
 	if query_req.Category != "" {
-		if query_req.Category != "business" && query_req.Category != "entertainment" && query_req.Category != "general" && query_req.Category != "health" && query_req.Category != "science" && query_req.Category != "sports" && query_req.Category != "technology" {
-			return NewsAPIResponse{}, fmt.Errorf("invalid category: %s. valid categories are business, entertainment, general, health, science, sports, technology", query_req.Category)
-		}
-
 		q.Set("category", query_req.Category)
 	}
 
 	if query_req.Country != "" {
-		if query_req.Country != "us" {
-			return NewsAPIResponse{}, fmt.Errorf("invalid country code: %s, valid country code is us", query_req.Country)
-		}
-
 		q.Set("country", query_req.Country)
 	}
 
+	if query_req.Sources != "" {
+		q.Set("sources", query_req.Sources)
+	}
+
 	if query_req.Q != "" {
 		q.Set("q", query_req.Q)
 	}
@@ -193,8 +277,92 @@ func NewsAPITopHeadlines(api_key string, query_req NewsAPITopHeadlinesReq) (News
 	// decode response from json to struct
 	var newsAPIResponse NewsAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&newsAPIResponse); err != nil {
+		// errors of an outage or a proxy may not be json, keep the status so the caller can tell them apart
+		if resp.StatusCode != http.StatusOK {
+			return NewsAPIResponse{Status: "error", Message: resp.Status, StatusCode: resp.StatusCode}, nil
+		}
 		return NewsAPIResponse{}, err
 	}
+	newsAPIResponse.StatusCode = resp.StatusCode
 
 	return newsAPIResponse, nil
 }
+
+// Validate checks the source filters before the request is made
+func (query_req NewsAPISourcesReq) Validate() error {
+	if query_req.Category != "" && !slices.Contains(categoryOptions, query_req.Category) {
+		return fmt.Errorf("invalid category: %s. valid categories are %s", query_req.Category, strings.Join(categoryOptions, ", "))
+	}
+
+	if query_req.Language != "" && !slices.Contains(languageOptions, query_req.Language) {
+		return fmt.Errorf("invalid language: %s. valid languages are %s", query_req.Language, strings.Join(languageOptions, ", "))
+	}
+
+	if query_req.Country != "" && !slices.Contains(countryOptions, query_req.Country) {
+		return fmt.Errorf("invalid country code: %s. valid country codes are %s", query_req.Country, strings.Join(countryOptions, ", "))
+	}
+
+	return nil
+}
+
+// NewsAPISources lists the news sources of NewsAPI, their ids can be used as the sources filter of the other requests
+func NewsAPISources(api_key string, query_req NewsAPISourcesReq) (NewsAPISourcesResponse, error) {
+
+	if api_key == "" {
+		return NewsAPISourcesResponse{}, nil
+	}
+
+	if err := query_req.Validate(); err != nil {
+		return NewsAPISourcesResponse{}, err
+	}
+
+	httpClient := &http.Client{}
+	req, err := http.NewRequest("GET", NewsAPIURLSources, nil)
+	if err != nil {
+		return NewsAPISourcesResponse{}, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+api_key)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	q := req.URL.Query()
+
+	if query_req.Category != "" {
+		q.Set("category", query_req.Category)
+	}
+
+	if query_req.Language != "" {
+		q.Set("language", query_req.Language)
+	}
+
+	if query_req.Country != "" {
+		q.Set("country", query_req.Country)
+	}
+
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return NewsAPISourcesResponse{}, err
+	}
+
+	defer func() {
+		if resp.StatusCode != http.StatusOK {
+			io.ReadAll(resp.Body)
+		}
+		resp.Body.Close()
+	}()
+
+	var sourcesResponse NewsAPISourcesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sourcesResponse); err != nil {
+		// errors of an outage or a proxy may not be json, keep the status so the caller can tell them apart
+		if resp.StatusCode != http.StatusOK {
+			return NewsAPISourcesResponse{Status: "error", Message: resp.Status, StatusCode: resp.StatusCode}, nil
+		}
+		return NewsAPISourcesResponse{}, err
+	}
+	sourcesResponse.StatusCode = resp.StatusCode
+
+	return sourcesResponse, nil
+}
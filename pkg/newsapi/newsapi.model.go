@@ -7,24 +7,40 @@ const (
 	NewsAPIURLSources      = NewsAPIURLTopHeadlines + "/sources"
 )
 
+var (
+	sortByOptions   = []string{"relevancy", "popularity", "publishedAt"}
+	searchInOptions = []string{"title", "description", "content"}
+	languageOptions = []string{"ar", "de", "en", "es", "fr", "he", "it", "nl", "no", "pt", "ru", "sv", "ud", "zh"}
+	categoryOptions = []string{"business", "entertainment", "general", "health", "science", "sports", "technology"}
+	// ISO 3166-1 codes of the countries NewsAPI has headlines and sources for
+	countryOptions = []string{
+		"ae", "ar", "at", "au", "be", "bg", "br", "ca", "ch", "cn", "co", "cu", "cz", "de", "eg", "fr", "gb", "gr",
+		"hk", "hu", "id", "ie", "il", "in", "it", "jp", "kr", "lt", "lv", "ma", "mx", "my", "ng", "nl", "no", "nz",
+		"ph", "pl", "pt", "ro", "rs", "ru", "sa", "se", "sg", "si", "sk", "th", "tr", "tw", "ua", "us", "ve", "za",
+	}
+)
+
+// most sources one request can ask for
+const maxSources = 20
+
 type NewsAPIEverythingReq struct {
 	Q              string `json:"q,omitempty"`
-	Searchin       string `json:"searchIn,omitempty"`
+	Searchin       string `json:"searchIn,omitempty"` // comma separated fields to search the q in, options: title, description, content
 	PageSize       int    `json:"pageSize,omitempty"`
 	Page           int    `json:"page,omitempty"`
 	SortBy         string `json:"sortBy,omitempty"`         // options: relevancy, popularity, publishedAt
-	From           string `json:"from,omitempty"`           // YYYY-MM-DD
-	To             string `json:"to,omitempty"`             // YYYY-MM-DD
+	From           string `json:"from,omitempty"`           // YYYY-MM-DD or YYYY-MM-DDTHH:MM:SSZ
+	To             string `json:"to,omitempty"`             // YYYY-MM-DD or YYYY-MM-DDTHH:MM:SSZ
 	Domains        string `json:"domains,omitempty"`        // comma separated list of domains to include in the results
 	ExcludeDomains string `json:"excludeDomains,omitempty"` // comma separated list of domains to exclude from the results
 	Language       string `json:"language,omitempty"`       // ISO 639-1 code of the language to restrict the results to, Possible options: ar, de, en, es, fr, he, it, nl, no, pt, ru, sv, ud, zh.
-	// Sources        string `json:"sources,omitempty"`        // comma separated list of identifiers for the news sources or blogs to include in the results
+	Sources        string `json:"sources,omitempty"`        // comma separated list of identifiers for the news sources or blogs to include in the results, 20 at most
 }
 
 type NewsAPITopHeadlinesReq struct {
-	Country  string `json:"country,omitempty"`  // ISO 3166-1 code of the country to restrict the results to BUT for this in Docs just have can use one country code -> "us"
+	Country  string `json:"country,omitempty"`  // ISO 3166-1 code of the country to restrict the results to, like us or id
 	Category string `json:"category,omitempty"` // options: business, entertainment, general, health, science, sports, technology
-	// Sources  string `json:"sources,omitempty"`  // comma separated list of identifiers for the news sources or blogs to include in the results
+	Sources  string `json:"sources,omitempty"`  // comma separated list of identifiers for the news sources or blogs to include in the results, can not be mixed with country or category
 	Q        string `json:"q,omitempty"`        // keywords or a phrase to search for in the article title and body
 	PageSize int    `json:"pageSize,omitempty"` // number of results to return per page (1-100)
 	Page     int    `json:"page,omitempty"`     // page number to retrieve (1-indexed)
@@ -52,6 +68,33 @@ type NewsAPIResponse struct {
 	TotalResults int       `json:"totalResults"`
 	Articles     []Article `json:"articles"`
 	// for error response
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code       string `json:"code"` // like rateLimited or apiKeyInvalid
+	Message    string `json:"message"`
+	StatusCode int    `json:"-"` // http status of the answer
+}
+
+type NewsAPISourcesReq struct {
+	Category string `json:"category,omitempty"` // options: business, entertainment, general, health, science, sports, technology
+	Language string `json:"language,omitempty"` // ISO 639-1 code of the language of the sources
+	Country  string `json:"country,omitempty"`  // ISO 3166-1 code of the country of the sources
+}
+
+type Source struct {
+	Id          string `json:"id" example:"bbc-news"` // identifier to use in the sources filter of the other requests
+	Name        string `json:"name" example:"BBC News"`
+	Description string `json:"description" example:"Use BBC News for up-to-the-minute news, breaking news, video, audio and feature stories."`
+	Url         string `json:"url" example:"https://www.bbc.co.uk/news"`
+	Category    string `json:"category" example:"general"`
+	Language    string `json:"language" example:"en"`
+	Country     string `json:"country" example:"gb"`
+}
+
+type NewsAPISourcesResponse struct {
+	Status string `json:"status"` // "ok" or "error"
+	// for success response
+	Sources []Source `json:"sources"`
+	// for error response
+	Code       string `json:"code"` // like rateLimited or apiKeyInvalid
+	Message    string `json:"message"`
+	StatusCode int    `json:"-"` // http status of the answer
 }
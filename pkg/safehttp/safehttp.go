@@ -0,0 +1,111 @@
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// MaxRedirects is how many redirects the client follows before giving up
+const MaxRedirects = 5
+
+var ErrBlockedAddress = errors.New("address is not allowed")
+
+// ranges that are not public but are not covered by the checks of net.IP
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // this network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier grade nat
+	netip.MustParsePrefix("192.0.0.0/24"),  // ietf protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // nat64, can point at any ipv4 address
+}
+
+// Client fetches urls given by the api callers or found in their feeds. It only connects to public addresses,
+// the check runs on the resolved ip of every connection so dns names and redirects to internal hosts are refused too
+var Client = New(60 * time.Second)
+
+// New returns a guarded client with the timeout for the whole request
+func New(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: control,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// no proxy from the environment, the dialer would only check the address of the proxy
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 30 * time.Second,
+			MaxIdleConns:          20,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// ValidateURL checks the url is an absolute http or https url
+func ValidateURL(raw_url string) error {
+	u, err := url.Parse(raw_url)
+	if err != nil {
+		return fmt.Errorf("invalid url: %s", raw_url)
+	}
+
+	return validateURL(u)
+}
+
+func validateURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid url: %s, use an http or https url", u.Redacted())
+	}
+
+	return nil
+}
+
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", MaxRedirects)
+	}
+
+	return validateURL(req.URL)
+}
+
+// control runs after the dns lookup with the ip the dialer is about to connect to
+func control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+
+	return nil
+}
+
+// IsPublic reports if the ip is not a loopback, private, link local, unspecified, multicast or reserved address
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.Zone() != "" || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
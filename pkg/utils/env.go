@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnvInt reads an integer env variable and falls back to def when it is empty or invalid
+func GetEnvInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// GetEnvDuration reads a duration env variable like 5s or 1m and falls back to def when it is empty or invalid
+func GetEnvDuration(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+
+	return value
+}
+
+// GetEnvList reads a comma separated env variable, empty items are left out
+func GetEnvList(key string) []string {
+	items := []string{}
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -39,3 +39,11 @@ func ResponseError(c *fiber.Ctx, code int, message string) error {
 		Message: message,
 	})
 }
+
+func ResponseErrorWithData(c *fiber.Ctx, code int, message string, data interface{}) error {
+	return c.Status(code).JSON(&DataResponse{
+		Data:    data,
+		Message: message,
+		Error:   true,
+	})
+}
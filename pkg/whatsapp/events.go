@@ -0,0 +1,206 @@
+package whatsapp
+
+import (
+	"log"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// event types passed to the EventHandler
+const (
+	EventMessage      = "message"
+	EventReceipt      = "receipt"
+	EventConnected    = "connected"
+	EventDisconnected = "disconnected"
+	EventLoggedOut    = "logged_out"
+	EventQR           = "qr"     // a new QR code to scan, it rotates every few seconds until the session is paired
+	EventPaired       = "paired" // the session was linked to a phone with the QR code or a pairing code
+)
+
+// receipt types of ReceiptEvent for the receipts of the recipients, the other whatsmeow types are passed as they are
+const (
+	ReceiptDelivered = "delivered"
+	ReceiptRead      = "read"
+	ReceiptPlayed    = "played"
+)
+
+// Event is a whatsmeow event of one session turned into a json friendly shape
+type Event struct {
+	Session   string    `json:"session"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data,omitempty"` // MessageEvent, ReceiptEvent, LoggedOutEvent, QREvent or PairedEvent, empty for the connection events
+}
+
+// MessageEvent is a message received by, or sent from another device of, the session
+type MessageEvent struct {
+	ID        string    `json:"id"`
+	Chat      string    `json:"chat"`   // group JID for group messages, the sender JID otherwise
+	Sender    string    `json:"sender"` // JID of the sender
+	IsGroup   bool      `json:"is_group"`
+	FromMe    bool      `json:"from_me"`
+	PushName  string    `json:"push_name,omitempty"`
+	Kind      string    `json:"kind"`           // text, image, video, audio, document, sticker, location, reaction or other
+	Text      string    `json:"text,omitempty"` // text of the message or caption of the media
+	Latitude  float64   `json:"latitude,omitempty"`
+	Longitude float64   `json:"longitude,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReceiptEvent tells that messages sent by the session were delivered, read or played
+type ReceiptEvent struct {
+	MessageIDs []string  `json:"message_ids"`
+	Chat       string    `json:"chat"`
+	Sender     string    `json:"sender"` // who delivered or read the messages
+	IsGroup    bool      `json:"is_group"`
+	Receipt    string    `json:"receipt"` // delivered, read, played or the raw whatsmeow receipt type
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+type LoggedOutEvent struct {
+	Reason string `json:"reason"`
+}
+
+// QREvent holds the QR code that links the session, whoever scans it takes the session over
+type QREvent struct {
+	Code string `json:"code"`
+}
+
+type PairedEvent struct {
+	JID          string `json:"jid"` // device the session is now linked to
+	BusinessName string `json:"business_name,omitempty"`
+	Platform     string `json:"platform,omitempty"`
+}
+
+// EventHandler receives the events of every session, it is called from the whatsmeow event loop
+// so it should return quickly and do slow work in the background
+type EventHandler func(evt Event)
+
+// AddEventHandler registers a handler for the events of all sessions, add them before Start
+// so the events of the sessions connected on start are not missed
+func (m *Manager) AddEventHandler(handler EventHandler) {
+	m.handlersMu.Lock()
+	defer m.handlersMu.Unlock()
+
+	m.handlers = append(m.handlers, handler)
+}
+
+func (m *Manager) dispatch(evt Event) {
+	m.handlersMu.RLock()
+	handlers := m.handlers
+	m.handlersMu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(evt)
+	}
+}
+
+// handleEvent is registered on the whatsmeow client of the session
+func (w *Session) handleEvent(raw any) {
+	evt := Event{
+		Session:   w.name,
+		Timestamp: time.Now(),
+	}
+
+	switch e := raw.(type) {
+	case *events.Message:
+		evt.Type = EventMessage
+		evt.Data = messageEvent(e)
+	case *events.Receipt:
+		evt.Type = EventReceipt
+		evt.Data = receiptEvent(e)
+	case *events.Connected:
+		evt.Type = EventConnected
+	case *events.Disconnected:
+		evt.Type = EventDisconnected
+	case *events.PairSuccess:
+		evt.Type = EventPaired
+		evt.Data = PairedEvent{JID: e.ID.String(), BusinessName: e.BusinessName, Platform: e.Platform}
+	case *events.LoggedOut:
+		evt.Type = EventLoggedOut
+		evt.Data = LoggedOutEvent{Reason: e.Reason.String()}
+
+		// the device was unlinked from the phone, start over with a new QR login on next use
+		log.Printf("WhatsApp session %s logged out: %s\n", w.name, e.Reason.String())
+		go func() {
+			w.client.Disconnect()
+			w.manager.reset(w.name)
+		}()
+	default:
+		return
+	}
+
+	w.manager.dispatch(evt)
+}
+
+// emit dispatches an event of the session that does not come from the whatsmeow event handler
+func (w *Session) emit(event_type string, data any) {
+	w.manager.dispatch(Event{
+		Session:   w.name,
+		Type:      event_type,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+}
+
+func messageEvent(e *events.Message) MessageEvent {
+	msg := MessageEvent{
+		ID:        e.Info.ID,
+		Chat:      e.Info.Chat.String(),
+		Sender:    e.Info.Sender.ToNonAD().String(),
+		IsGroup:   e.Info.IsGroup,
+		FromMe:    e.Info.IsFromMe,
+		PushName:  e.Info.PushName,
+		Timestamp: e.Info.Timestamp,
+	}
+
+	content := e.Message
+	switch {
+	case content.GetConversation() != "":
+		msg.Kind, msg.Text = "text", content.GetConversation()
+	case content.GetExtendedTextMessage() != nil:
+		msg.Kind, msg.Text = "text", content.GetExtendedTextMessage().GetText()
+	case content.GetImageMessage() != nil:
+		msg.Kind, msg.Text = "image", content.GetImageMessage().GetCaption()
+	case content.GetVideoMessage() != nil:
+		msg.Kind, msg.Text = "video", content.GetVideoMessage().GetCaption()
+	case content.GetAudioMessage() != nil:
+		msg.Kind = "audio"
+	case content.GetDocumentMessage() != nil:
+		msg.Kind, msg.Text = "document", content.GetDocumentMessage().GetCaption()
+	case content.GetStickerMessage() != nil:
+		msg.Kind = "sticker"
+	case content.GetLocationMessage() != nil:
+		msg.Kind = "location"
+		msg.Latitude = content.GetLocationMessage().GetDegreesLatitude()
+		msg.Longitude = content.GetLocationMessage().GetDegreesLongitude()
+	case content.GetLiveLocationMessage() != nil:
+		msg.Kind = "location"
+		msg.Latitude = content.GetLiveLocationMessage().GetDegreesLatitude()
+		msg.Longitude = content.GetLiveLocationMessage().GetDegreesLongitude()
+	case content.GetReactionMessage() != nil:
+		msg.Kind, msg.Text = "reaction", content.GetReactionMessage().GetText()
+	default:
+		msg.Kind = "other"
+	}
+
+	return msg
+}
+
+func receiptEvent(e *events.Receipt) ReceiptEvent {
+	receipt := string(e.Type)
+	if e.Type == types.ReceiptTypeDelivered {
+		receipt = ReceiptDelivered
+	}
+
+	return ReceiptEvent{
+		MessageIDs: e.MessageIDs,
+		Chat:       e.Chat.String(),
+		Sender:     e.Sender.ToNonAD().String(),
+		IsGroup:    e.IsGroup,
+		Receipt:    receipt,
+		Timestamp:  e.Timestamp,
+	}
+}
@@ -0,0 +1,82 @@
+package whatsapp
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// ParseRecipient turns a send target into a JID. A plain number like 6285727771234 is a personal chat,
+// a full JID like 120363025246125486@g.us addresses a group
+func ParseRecipient(to string) (types.JID, error) {
+	to = strings.TrimSpace(to)
+	if to == "" {
+		return types.EmptyJID, fmt.Errorf("recipient is empty")
+	}
+
+	if !strings.Contains(to, "@") {
+		return types.NewJID(to, types.DefaultUserServer), nil
+	}
+
+	jid, err := types.ParseJID(to)
+	if err != nil {
+		return types.EmptyJID, fmt.Errorf("invalid recipient %s: %v", to, err)
+	}
+
+	if jid.Server != types.DefaultUserServer && jid.Server != types.GroupServer {
+		return types.EmptyJID, fmt.Errorf("invalid recipient %s: only @%s and @%s are supported", to, types.DefaultUserServer, types.GroupServer)
+	}
+
+	return jid, nil
+}
+
+// IsGroup reports if the send target is a group JID
+func IsGroup(to string) bool {
+	return strings.HasSuffix(strings.TrimSpace(to), "@"+types.GroupServer)
+}
+
+// GetJoinedGroups returns the groups the logged in account is a member of
+func (w *Session) GetJoinedGroups() ([]*types.GroupInfo, error) {
+	if err := w.checkReady(); err != nil {
+		return nil, err
+	}
+
+	groups, err := w.client.GetJoinedGroups()
+	if err != nil {
+		return nil, fmt.Errorf("error getting joined groups: %v", err)
+	}
+
+	return groups, nil
+}
+
+// JoinGroupWithLink joins the group of the invite link, the link can be the full
+// https://chat.whatsapp.com/<code> url or only the code
+func (w *Session) JoinGroupWithLink(invite_link string) (*types.GroupInfo, error) {
+	if err := w.checkReady(); err != nil {
+		return nil, err
+	}
+
+	code := strings.TrimSpace(invite_link)
+	code = strings.TrimPrefix(code, "http://")
+	code = strings.TrimPrefix(code, "https://")
+	code = strings.TrimPrefix(code, "chat.whatsapp.com/")
+	code = strings.TrimSuffix(code, "/")
+	if code == "" || strings.Contains(code, "/") {
+		return nil, fmt.Errorf("invalid invite link: %s", invite_link)
+	}
+
+	jid, err := w.client.JoinGroupWithLink(whatsmeow.InviteLinkPrefix + code)
+	if err != nil {
+		return nil, fmt.Errorf("error joining group: %v", err)
+	}
+
+	// groups with join approval return the request instead of the group, so the info may not be available yet
+	info, err := w.client.GetGroupInfo(jid)
+	if err != nil {
+		return &types.GroupInfo{JID: jid}, nil
+	}
+
+	return info, nil
+}
@@ -0,0 +1,142 @@
+package whatsapp
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// LimitOptions controls how fast a session sends, sending too many messages too fast is the usual reason
+// WhatsApp bans a number. Zero values use the defaults and a negative value turns that limit off
+type LimitOptions struct {
+	PerMinute         int           // messages the session may send per minute, refilled evenly over the minute
+	Burst             int           // messages that can go out back to back before the per minute rate applies
+	RecipientCooldown time.Duration // minimum wait between two messages to the same recipient
+	MinJitter         time.Duration // random pause before every send is between MinJitter and MaxJitter
+	MaxJitter         time.Duration
+}
+
+func DefaultLimitOptions() LimitOptions {
+	return LimitOptions{
+		PerMinute:         20,
+		Burst:             5,
+		RecipientCooldown: 5 * time.Second,
+		MinJitter:         500 * time.Millisecond,
+		MaxJitter:         2 * time.Second,
+	}
+}
+
+// withDefaults fills the zero values with the defaults and turns the negative ones into zero, which means off
+func (o LimitOptions) withDefaults() LimitOptions {
+	defaults := DefaultLimitOptions()
+	if o.PerMinute == 0 {
+		o.PerMinute = defaults.PerMinute
+	}
+	if o.Burst == 0 {
+		o.Burst = min(defaults.Burst, max(o.PerMinute, 1))
+	}
+	if o.RecipientCooldown == 0 {
+		o.RecipientCooldown = defaults.RecipientCooldown
+	}
+	if o.MinJitter == 0 {
+		o.MinJitter = defaults.MinJitter
+	}
+	if o.MaxJitter == 0 {
+		o.MaxJitter = max(defaults.MaxJitter, o.MinJitter)
+	}
+
+	o.PerMinute = max(o.PerMinute, 0)
+	o.Burst = max(o.Burst, 1)
+	o.RecipientCooldown = max(o.RecipientCooldown, 0)
+	o.MinJitter = max(o.MinJitter, 0)
+	o.MaxJitter = max(o.MaxJitter, o.MinJitter)
+
+	return o
+}
+
+// RateLimitError is returned instead of sending when the limiter does not allow the message yet,
+// nothing was sent and the message can be tried again after RetryAfter
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("deferred by the rate limiter, retry in %s", e.RetryAfter.Round(time.Millisecond))
+}
+
+// limiter is the token bucket of one session plus the time of the last message to every recipient
+type limiter struct {
+	opts   LimitOptions
+	mu     sync.Mutex
+	tokens float64
+	filled time.Time
+	last   map[string]time.Time
+}
+
+func newLimiter(opts LimitOptions) *limiter {
+	opts = opts.withDefaults()
+
+	return &limiter{
+		opts:   opts,
+		tokens: float64(opts.Burst),
+		filled: time.Now(),
+		last:   map[string]time.Time{},
+	}
+}
+
+// reserve takes a token for a message to the recipient and returns zero, or returns how long to wait
+// when the bucket is empty or the recipient got a message too recently. Nothing is taken when it has to wait
+func (l *limiter) reserve(recipient string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	wait := time.Duration(0)
+
+	if l.opts.RecipientCooldown > 0 {
+		if last, ok := l.last[recipient]; ok {
+			wait = max(wait, last.Add(l.opts.RecipientCooldown).Sub(now))
+		}
+	}
+
+	if l.opts.PerMinute > 0 {
+		per_token := time.Minute / time.Duration(l.opts.PerMinute)
+		l.tokens = min(float64(l.opts.Burst), l.tokens+float64(now.Sub(l.filled))/float64(per_token))
+		l.filled = now
+
+		if l.tokens < 1 {
+			wait = max(wait, time.Duration((1-l.tokens)*float64(per_token)))
+		}
+	}
+
+	if wait > 0 {
+		return wait
+	}
+
+	if l.opts.PerMinute > 0 {
+		l.tokens--
+	}
+	l.last[recipient] = now
+
+	// recipients past their cooldown do not need to be remembered anymore
+	if len(l.last) > 1000 {
+		for number, last := range l.last {
+			if now.Sub(last) > l.opts.RecipientCooldown {
+				delete(l.last, number)
+			}
+		}
+	}
+
+	return 0
+}
+
+// jitter is the random pause before a send, so the messages do not leave at a machine like steady pace
+func (l *limiter) jitter() time.Duration {
+	spread := l.opts.MaxJitter - l.opts.MinJitter
+	if spread <= 0 {
+		return l.opts.MinJitter
+	}
+
+	return l.opts.MinJitter + rand.N(spread)
+}
@@ -0,0 +1,316 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/momokii/go-wa-notifier/pkg/safehttp"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+type MediaType string
+
+const (
+	MediaImage    MediaType = "image"
+	MediaDocument MediaType = "document"
+	MediaAudio    MediaType = "audio"
+	MediaVideo    MediaType = "video"
+)
+
+// MaxMediaSize is the biggest media file accepted for sending, the WhatsApp limit for video and audio
+const MaxMediaSize = 16 << 20
+
+// ParseMediaType checks the media type name, an empty name is guessed from the mimetype
+func ParseMediaType(media_type, mimetype string) (MediaType, error) {
+	if media_type == "" {
+		switch {
+		case strings.HasPrefix(mimetype, "image/"):
+			return MediaImage, nil
+		case strings.HasPrefix(mimetype, "video/"):
+			return MediaVideo, nil
+		case strings.HasPrefix(mimetype, "audio/"):
+			return MediaAudio, nil
+		default:
+			return MediaDocument, nil
+		}
+	}
+
+	switch MediaType(media_type) {
+	case MediaImage, MediaDocument, MediaAudio, MediaVideo:
+		return MediaType(media_type), nil
+	default:
+		return "", fmt.Errorf("invalid media type: %s, valid types are image, document, audio, video", media_type)
+	}
+}
+
+// ErrFetchMedia is returned for every url that can not be fetched, the reason is only logged
+// so the callers can not use the errors to probe the network of the server
+var ErrFetchMedia = errors.New("failed to fetch media from the url")
+
+// FetchMedia downloads a media file from a public http or https url, the mimetype is taken from the response
+// or detected from the content and the file name from the url path
+func FetchMedia(url string) (data []byte, mimetype string, file_name string, err error) {
+	if err := safehttp.ValidateURL(url); err != nil {
+		return nil, "", "", err
+	}
+
+	resp, err := safehttp.Client.Get(url)
+	if err != nil {
+		log.Printf("WhatsApp: failed to fetch media: %s\n", err.Error())
+		return nil, "", "", ErrFetchMedia
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("WhatsApp: failed to fetch media %s: %s\n", resp.Request.URL.Redacted(), resp.Status)
+		return nil, "", "", ErrFetchMedia
+	}
+
+	// read one byte more than allowed to know if the file is too big
+	data, err = io.ReadAll(io.LimitReader(resp.Body, MaxMediaSize+1))
+	if err != nil {
+		log.Printf("WhatsApp: failed to read media %s: %s\n", resp.Request.URL.Redacted(), err.Error())
+		return nil, "", "", ErrFetchMedia
+	}
+
+	if len(data) > MaxMediaSize {
+		return nil, "", "", fmt.Errorf("media is bigger than %d MB", MaxMediaSize>>20)
+	}
+
+	mimetype, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mimetype == "" || mimetype == "application/octet-stream" {
+		mimetype = http.DetectContentType(data)
+	}
+
+	file_name = path.Base(resp.Request.URL.Path)
+	if file_name == "/" || file_name == "." {
+		file_name = ""
+	}
+
+	return data, mimetype, file_name, nil
+}
+
+// how long an upload is reused for other recipients and retries of the same media
+const uploadCacheTTL = time.Hour
+
+type cachedUpload struct {
+	mu       sync.Mutex // held while the media is uploaded so concurrent sends of the same key wait for it
+	resp     whatsmeow.UploadResponse
+	uploaded time.Time
+}
+
+// uploadCache keeps the uploads of a session by media key, the encrypted file on the WhatsApp servers
+// can be sent to any number of recipients
+type uploadCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedUpload
+}
+
+func newUploadCache() *uploadCache {
+	return &uploadCache{entries: map[string]*cachedUpload{}}
+}
+
+func (c *uploadCache) entry(key string) *cachedUpload {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// drop the expired and failed uploads that are not in use while here, the cache has at most a few entries per broadcast
+	for k, e := range c.entries {
+		if e.mu.TryLock() {
+			expired := time.Since(e.uploaded) > uploadCacheTTL
+			e.mu.Unlock()
+			if expired {
+				delete(c.entries, k)
+			}
+		}
+	}
+
+	e, ok := c.entries[key]
+	if !ok {
+		e = &cachedUpload{}
+		c.entries[key] = e
+	}
+
+	return e
+}
+
+// upload encrypts and uploads the media to the WhatsApp servers. With a media key in the options
+// the upload is reused by the next sends of the same key until it is an hour old
+func (w *Session) upload(data []byte, media_type whatsmeow.MediaType, opts SendOptions) (whatsmeow.UploadResponse, error) {
+	if opts.MediaKey == "" {
+		return w.uploadData(data, media_type)
+	}
+
+	e := w.uploads.entry(opts.MediaKey + "/" + string(media_type))
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.uploaded.IsZero() && time.Since(e.uploaded) <= uploadCacheTTL {
+		return e.resp, nil
+	}
+
+	resp, err := w.uploadData(data, media_type)
+	if err != nil {
+		return whatsmeow.UploadResponse{}, err
+	}
+	e.resp, e.uploaded = resp, time.Now()
+
+	return resp, nil
+}
+
+func (w *Session) uploadData(data []byte, media_type whatsmeow.MediaType) (whatsmeow.UploadResponse, error) {
+	resp, err := w.client.Upload(context.Background(), data, media_type)
+	if err != nil {
+		return whatsmeow.UploadResponse{}, fmt.Errorf("error uploading media: %v", err)
+	}
+
+	return resp, nil
+}
+
+// SendImage uploads the image and sends it with an optional caption
+func (w *Session) SendImage(to string, data []byte, mimetype, caption string, opts SendOptions) (whatsmeow.SendResponse, error) {
+	// the limiter goes first, a deferred message must not upload the file on every retry
+	jid, err := w.prepare(to)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	uploaded, err := w.upload(data, whatsmeow.MediaImage, opts)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	return w.deliver(jid, &waE2E.Message{
+		ImageMessage: &waE2E.ImageMessage{
+			Caption:       optionalString(caption),
+			Mimetype:      proto.String(mimetype),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}, opts)
+}
+
+// SendDocument uploads the file and sends it as a document with its file name and an optional caption
+func (w *Session) SendDocument(to string, data []byte, mimetype, file_name, caption string, opts SendOptions) (whatsmeow.SendResponse, error) {
+	// the limiter goes first, a deferred message must not upload the file on every retry
+	jid, err := w.prepare(to)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	uploaded, err := w.upload(data, whatsmeow.MediaDocument, opts)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	if file_name == "" {
+		file_name = "document"
+	}
+
+	return w.deliver(jid, &waE2E.Message{
+		DocumentMessage: &waE2E.DocumentMessage{
+			Caption:       optionalString(caption),
+			Title:         proto.String(file_name),
+			FileName:      proto.String(file_name),
+			Mimetype:      proto.String(mimetype),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}, opts)
+}
+
+// SendAudio uploads the audio and sends it, WhatsApp does not show captions on audio messages
+func (w *Session) SendAudio(to string, data []byte, mimetype string, opts SendOptions) (whatsmeow.SendResponse, error) {
+	// the limiter goes first, a deferred message must not upload the file on every retry
+	jid, err := w.prepare(to)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	uploaded, err := w.upload(data, whatsmeow.MediaAudio, opts)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	return w.deliver(jid, &waE2E.Message{
+		AudioMessage: &waE2E.AudioMessage{
+			Mimetype:      proto.String(mimetype),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}, opts)
+}
+
+// SendVideo uploads the video and sends it with an optional caption
+func (w *Session) SendVideo(to string, data []byte, mimetype, caption string, opts SendOptions) (whatsmeow.SendResponse, error) {
+	// the limiter goes first, a deferred message must not upload the file on every retry
+	jid, err := w.prepare(to)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	uploaded, err := w.upload(data, whatsmeow.MediaVideo, opts)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+
+	return w.deliver(jid, &waE2E.Message{
+		VideoMessage: &waE2E.VideoMessage{
+			Caption:       optionalString(caption),
+			Mimetype:      proto.String(mimetype),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}, opts)
+}
+
+// SendMedia sends the media with the method matching its type
+func (w *Session) SendMedia(to string, media_type MediaType, data []byte, mimetype, file_name, caption string, opts SendOptions) (whatsmeow.SendResponse, error) {
+	switch media_type {
+	case MediaImage:
+		return w.SendImage(to, data, mimetype, caption, opts)
+	case MediaDocument:
+		return w.SendDocument(to, data, mimetype, file_name, caption, opts)
+	case MediaAudio:
+		return w.SendAudio(to, data, mimetype, opts)
+	case MediaVideo:
+		return w.SendVideo(to, data, mimetype, caption, opts)
+	default:
+		return whatsmeow.SendResponse{}, fmt.Errorf("invalid media type: %s", media_type)
+	}
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return proto.String(value)
+}
@@ -0,0 +1,205 @@
+package whatsapp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// how long the IsOnWhatsApp answer of a number is reused, numbers that are not on WhatsApp
+// are checked again sooner because the owner may register later
+const (
+	numberCacheTTL         = 24 * time.Hour
+	numberCacheNegativeTTL = time.Hour
+)
+
+// country code used for numbers written in the national format, like 08123456789
+const defaultCountryCode = "62"
+
+// NumberCheck is the validation result of one send target
+type NumberCheck struct {
+	Input      string // number or JID as given in the request
+	Number     string // normalized number without +, or the group JID
+	IsGroup    bool
+	Valid      bool
+	OnWhatsApp bool   // only set when the number was checked with IsOnWhatsApp
+	Reason     string // why the target is not valid
+}
+
+type numberCacheEntry struct {
+	onWhatsApp bool
+	user       string
+	expiresAt  time.Time
+}
+
+var (
+	numberCache   = map[string]numberCacheEntry{}
+	numberCacheMu sync.Mutex
+)
+
+// DefaultCountryCode returns the country code from DEFAULT_COUNTRY_CODE, 62 when it is not set
+func DefaultCountryCode() string {
+	code := strings.TrimPrefix(strings.TrimSpace(os.Getenv("DEFAULT_COUNTRY_CODE")), "+")
+	if code == "" {
+		return defaultCountryCode
+	}
+
+	return code
+}
+
+// NormalizeNumber turns a phone number into its E.164 digits without the +. Numbers can start with +, with the 00
+// international prefix, with the 0 national prefix which is replaced by country_code, or directly with the country code.
+// Spaces, dashes, dots and brackets are ignored
+func NormalizeNumber(number, country_code string) (string, error) {
+	replacer := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+	digits := replacer.Replace(strings.TrimSpace(number))
+
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		digits = digits[1:]
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	case strings.HasPrefix(digits, "0"):
+		digits = country_code + digits[1:]
+	}
+
+	if digits == "" {
+		return "", fmt.Errorf("number is empty")
+	}
+
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("number can only contain digits")
+		}
+	}
+
+	if digits[0] == '0' {
+		return "", fmt.Errorf("country code can not start with 0")
+	}
+
+	// E.164 allows at most 15 digits, the shortest numbers in use have 8 with the country code
+	if len(digits) < 8 || len(digits) > 15 {
+		return "", fmt.Errorf("number must have 8 to 15 digits with the country code, got %d", len(digits))
+	}
+
+	return digits, nil
+}
+
+// NormalizeRecipients validates the format of every send target without asking WhatsApp,
+// group JIDs are kept as they are and numbers are normalized with the default country code
+func NormalizeRecipients(inputs []string) []NumberCheck {
+	country_code := DefaultCountryCode()
+
+	checks := make([]NumberCheck, 0, len(inputs))
+	for _, input := range inputs {
+		check := NumberCheck{Input: input}
+
+		jid, err := ParseRecipient(input)
+		switch {
+		case err != nil:
+			check.Reason = err.Error()
+		case jid.Server == types.GroupServer:
+			check.Number = jid.String()
+			check.IsGroup = true
+			check.Valid = true
+		default:
+			number, err := NormalizeNumber(jid.User, country_code)
+			if err != nil {
+				check.Reason = err.Error()
+			} else {
+				check.Number = number
+				check.Valid = true
+			}
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// CheckNumbers normalizes the send targets and asks WhatsApp which numbers are registered, answers are cached
+// so repeated broadcasts to the same numbers do not query WhatsApp again. Groups are not checked
+func (w *Session) CheckNumbers(inputs []string) ([]NumberCheck, error) {
+	checks := NormalizeRecipients(inputs)
+
+	// collect the numbers without a cached answer so they are checked in one query
+	now := time.Now()
+	pending := []string{}
+	seen := map[string]bool{}
+
+	numberCacheMu.Lock()
+	for _, check := range checks {
+		if !check.Valid || check.IsGroup || seen[check.Number] {
+			continue
+		}
+
+		if entry, ok := numberCache[check.Number]; !ok || now.After(entry.expiresAt) {
+			pending = append(pending, check.Number)
+			seen[check.Number] = true
+		}
+	}
+	numberCacheMu.Unlock()
+
+	if len(pending) > 0 {
+		if err := w.checkReady(); err != nil {
+			return nil, err
+		}
+
+		phones := make([]string, 0, len(pending))
+		for _, number := range pending {
+			phones = append(phones, "+"+number)
+		}
+
+		resp, err := w.client.IsOnWhatsApp(phones)
+		if err != nil {
+			return nil, fmt.Errorf("error checking numbers: %v", err)
+		}
+
+		numberCacheMu.Lock()
+		for _, number := range pending {
+			numberCache[number] = numberCacheEntry{expiresAt: now.Add(numberCacheNegativeTTL)}
+		}
+		for _, info := range resp {
+			number := strings.TrimPrefix(info.Query, "+")
+			if !info.IsIn {
+				continue
+			}
+
+			numberCache[number] = numberCacheEntry{
+				onWhatsApp: true,
+				user:       info.JID.User,
+				expiresAt:  now.Add(numberCacheTTL),
+			}
+		}
+		numberCacheMu.Unlock()
+	}
+
+	numberCacheMu.Lock()
+	defer numberCacheMu.Unlock()
+
+	for i, check := range checks {
+		if !check.Valid || check.IsGroup {
+			continue
+		}
+
+		entry := numberCache[check.Number]
+		if !entry.onWhatsApp {
+			checks[i].Valid = false
+			checks[i].Reason = "number is not on WhatsApp"
+			continue
+		}
+
+		// WhatsApp may know the number under a slightly different form, send to that one
+		checks[i].OnWhatsApp = true
+		if entry.user != "" {
+			checks[i].Number = entry.user
+		}
+	}
+
+	return checks, nil
+}
@@ -0,0 +1,77 @@
+package whatsapp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+)
+
+var (
+	ErrAlreadyPaired = errors.New("whatsapp session is already paired")
+	ErrInvalidPhone  = errors.New("invalid phone number")
+)
+
+// how long PairPhone waits for the login websocket, the first QR code tells it is ready
+const pairReadyTimeout = 15 * time.Second
+
+// name shown in WhatsApp > Linked devices, WhatsApp only accepts common Browser (OS) names
+const pairClientName = "Chrome (Linux)"
+
+// PairPhone starts the login of the session with a pairing code instead of the QR code.
+// WhatsApp sends a notification to the phone of the number, where the returned 8 character code is entered
+// from Linked devices > Link with phone number instead. The code expires with the login, a few minutes at most
+func (m *Manager) PairPhone(name, phone string) (string, error) {
+	check := NormalizeRecipients([]string{phone})[0]
+	if check.IsGroup {
+		return "", fmt.Errorf("%w %s: a group can not be paired", ErrInvalidPhone, phone)
+	}
+	if !check.Valid {
+		return "", fmt.Errorf("%w %s: %s", ErrInvalidPhone, phone, check.Reason)
+	}
+
+	wa, err := m.Get(name)
+	if err != nil {
+		return "", err
+	}
+
+	if wa.client.Store.ID != nil {
+		return "", fmt.Errorf("%w: %s", ErrAlreadyPaired, SessionName(name))
+	}
+
+	// the login websocket closes once the QR codes run out, a new session client starts a fresh login
+	if !wa.client.IsConnected() {
+		m.reset(SessionName(name))
+		if wa, err = m.Get(name); err != nil {
+			return "", err
+		}
+	}
+
+	if err := wa.waitLoginReady(pairReadyTimeout); err != nil {
+		return "", err
+	}
+
+	code, err := wa.client.PairPhone(check.Number, true, whatsmeow.PairClientChrome, pairClientName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pairing code: %w", err)
+	}
+
+	return code, nil
+}
+
+// waitLoginReady waits until the login websocket sent its first QR code, the pairing code can only be asked after that
+func (w *Session) waitLoginReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, ready := w.GetQRCode(); ready {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("whatsapp login is not ready yet, try again in a moment")
+		}
+
+		time.Sleep(250 * time.Millisecond)
+	}
+}
@@ -0,0 +1,108 @@
+package whatsapp
+
+import (
+	"log"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// how long a session stays available after its last typed message before it goes unavailable again,
+// so a batch of messages is sent while the account looks online and it goes offline after the batch
+const presenceIdle = 30 * time.Second
+
+// typing speed and bounds of the simulated typing, long messages look pasted rather than typed for minutes
+const (
+	typingPerChar = 40 * time.Millisecond
+	typingMin     = 1 * time.Second
+	typingMax     = 8 * time.Second
+)
+
+// SendOptions changes how one message is sent
+type SendOptions struct {
+	Disconnect bool   // disconnect the client after the send, sessions are shared so this should rarely be used
+	Typing     bool   // show the account as typing in the chat for a time based on the message length before it is sent
+	MediaKey   string // identifies the media file, sends with the same key reuse its upload
+}
+
+// typingDuration is how long the text takes to type, audio is recorded for as long as a short text
+func typingDuration(text string) time.Duration {
+	return min(max(time.Duration(len([]rune(text)))*typingPerChar, typingMin), typingMax)
+}
+
+// typingOf returns the text that is typed for the message and the kind of chat presence to show,
+// audio shows recording and media use their caption
+func typingOf(message *waE2E.Message) (string, types.ChatPresenceMedia) {
+	switch {
+	case message.GetAudioMessage() != nil:
+		return "", types.ChatPresenceMediaAudio
+	case message.GetImageMessage() != nil:
+		return message.GetImageMessage().GetCaption(), types.ChatPresenceMediaText
+	case message.GetVideoMessage() != nil:
+		return message.GetVideoMessage().GetCaption(), types.ChatPresenceMediaText
+	case message.GetDocumentMessage() != nil:
+		return message.GetDocumentMessage().GetCaption(), types.ChatPresenceMediaText
+	default:
+		return message.GetConversation(), types.ChatPresenceMediaText
+	}
+}
+
+// simulateTyping makes the session available, shows composing in the chat for the typing time of the message
+// and sets it to paused again. Presence errors are only logged, they never stop the message
+func (w *Session) simulateTyping(jid types.JID, message *waE2E.Message) {
+	w.markAvailable()
+
+	text, media := typingOf(message)
+	if err := w.client.SendChatPresence(jid, types.ChatPresenceComposing, media); err != nil {
+		log.Printf("Failed to send typing of session %s: %s\n", w.name, err.Error())
+		return
+	}
+
+	time.Sleep(typingDuration(text))
+
+	if err := w.client.SendChatPresence(jid, types.ChatPresencePaused, media); err != nil {
+		log.Printf("Failed to send paused of session %s: %s\n", w.name, err.Error())
+	}
+}
+
+// markAvailable sets the session available when it is not yet and goes unavailable again once
+// no typed message was sent for presenceIdle
+func (w *Session) markAvailable() {
+	w.presenceMu.Lock()
+	defer w.presenceMu.Unlock()
+
+	// still available, only push the idle timeout further
+	if w.idleTimer != nil && w.idleTimer.Stop() {
+		w.idleTimer.Reset(presenceIdle)
+		return
+	}
+
+	if err := w.client.SendPresence(types.PresenceAvailable); err != nil {
+		log.Printf("Failed to set session %s available: %s\n", w.name, err.Error())
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(presenceIdle, func() { w.markUnavailable(timer) })
+	w.idleTimer = timer
+}
+
+// markUnavailable is fired by the idle timer, a timer replaced by a newer batch does nothing
+func (w *Session) markUnavailable(timer *time.Timer) {
+	w.presenceMu.Lock()
+	defer w.presenceMu.Unlock()
+
+	if w.idleTimer != timer {
+		return
+	}
+	w.idleTimer = nil
+
+	if !w.client.IsConnected() {
+		return
+	}
+
+	if err := w.client.SendPresence(types.PresenceUnavailable); err != nil {
+		log.Printf("Failed to set session %s unavailable: %s\n", w.name, err.Error())
+	}
+}
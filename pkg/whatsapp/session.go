@@ -0,0 +1,384 @@
+package whatsapp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/store/sqlstore"
+	"go.mau.fi/whatsmeow/types"
+	waLog "go.mau.fi/whatsmeow/util/log"
+)
+
+// DefaultSession is used when a request does not pick a session, it holds the account
+// that was paired before multiple sessions were supported
+const DefaultSession = "default"
+
+var (
+	ErrSessionNotFound = errors.New("whatsapp session not found")
+	ErrSessionExists   = errors.New("whatsapp session already exists")
+)
+
+var sessionNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+
+// SessionInfo is the state of one session
+type SessionInfo struct {
+	Name        string
+	JID         string // empty until the session is paired
+	IsConnected bool
+	IsLoggedIn  bool
+	QRReady     bool
+	CreatedAt   time.Time
+}
+
+// Manager keeps one client per session over the whatsmeow device store, the session names
+// and the device each one is paired with are stored in the wa_sessions table
+type Manager struct {
+	db        *sql.DB
+	container *sqlstore.Container
+	mu        sync.Mutex
+	sessions  map[string]*Session
+	loading   map[string]*loadingSession // sessions being connected by Get, outside of mu
+	limits    LimitOptions               // rate limits every session gets, each session has its own bucket
+
+	handlersMu sync.RWMutex
+	handlers   []EventHandler
+}
+
+// NewManager creates the whatsmeow device store on the given database and upgrades its tables,
+// the limits apply to each session on its own since every session is a separate WhatsApp account
+func NewManager(db *sql.DB, limits LimitOptions) (*Manager, error) {
+	container := sqlstore.NewWithDB(db, "postgres", waLog.Noop)
+	if err := container.Upgrade(); err != nil {
+		return nil, fmt.Errorf("failed to upgrade whatsmeow store: %w", err)
+	}
+
+	return &Manager{
+		db:        db,
+		container: container,
+		sessions:  map[string]*Session{},
+		loading:   map[string]*loadingSession{},
+		limits:    limits,
+	}, nil
+}
+
+// Migrate creates the sessions table if it does not exist yet
+func (m *Manager) Migrate(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS wa_sessions (
+			name       TEXT PRIMARY KEY,
+			jid        TEXT UNIQUE,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate sessions table: %w", err)
+	}
+
+	return nil
+}
+
+// Start gives a session to every paired device that has none yet and connects all paired sessions.
+// The first unknown device becomes the default session, so a deployment paired before sessions existed keeps working,
+// the others are named after their phone number
+func (m *Manager) Start(ctx context.Context) error {
+	devices, err := m.container.GetAllDevices()
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	rows, err := m.db.QueryContext(ctx, `SELECT name, COALESCE(jid, '') FROM wa_sessions`)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name, jid string
+		if err := rows.Scan(&name, &jid); err != nil {
+			rows.Close()
+			return err
+		}
+		known[name] = true
+		if jid != "" {
+			known[jid] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, device := range devices {
+		if device.ID == nil || known[device.ID.String()] {
+			continue
+		}
+
+		name := device.ID.User
+		if !known[DefaultSession] {
+			name = DefaultSession
+		}
+
+		if _, err := m.db.ExecContext(ctx, `INSERT INTO wa_sessions (name, jid) VALUES ($1, $2) ON CONFLICT DO NOTHING`, name, device.ID.String()); err != nil {
+			return err
+		}
+		known[name] = true
+	}
+
+	if !known[DefaultSession] {
+		if _, err := m.db.ExecContext(ctx, `INSERT INTO wa_sessions (name) VALUES ($1) ON CONFLICT DO NOTHING`, DefaultSession); err != nil {
+			return err
+		}
+	}
+
+	sessions, err := m.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range sessions {
+		if info.JID == "" {
+			continue
+		}
+
+		if _, err := m.Get(info.Name); err != nil {
+			log.Printf("Failed to connect WhatsApp session %s: %s\n", info.Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// SessionName returns the name of the default session when name is empty
+func SessionName(name string) string {
+	if name == "" {
+		return DefaultSession
+	}
+
+	return name
+}
+
+// loadingSession is a session being loaded, the other callers of Get for the same name wait for it
+type loadingSession struct {
+	done      chan struct{}
+	wa        *Session
+	err       error
+	forgotten bool // the session was deleted or logged out while it was loading
+}
+
+// Get returns the client of the session, connecting it on first use. An empty name is the default session.
+// The manager is only locked to look at the loaded sessions, so a session that is slow to connect
+// only holds back the callers of that session
+func (m *Manager) Get(name string) (*Session, error) {
+	name = SessionName(name)
+
+	m.mu.Lock()
+	if wa, ok := m.sessions[name]; ok {
+		m.mu.Unlock()
+		return wa, nil
+	}
+
+	if l, ok := m.loading[name]; ok {
+		m.mu.Unlock()
+		<-l.done
+		return l.wa, l.err
+	}
+
+	l := &loadingSession{done: make(chan struct{})}
+	m.loading[name] = l
+	m.mu.Unlock()
+
+	wa, err := m.load(name)
+
+	m.mu.Lock()
+	delete(m.loading, name)
+	if err == nil && l.forgotten {
+		wa.client.Disconnect()
+		wa, err = nil, fmt.Errorf("%w: %s", ErrSessionNotFound, name)
+	}
+	if err == nil {
+		m.sessions[name] = wa
+	}
+	l.wa, l.err = wa, err
+	m.mu.Unlock()
+	close(l.done)
+
+	return wa, err
+}
+
+// load reads the device of the session and connects its client
+func (m *Manager) load(name string) (*Session, error) {
+	var jid sql.NullString
+	err := m.db.QueryRow(`SELECT jid FROM wa_sessions WHERE name = $1`, name).Scan(&jid)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var device *store.Device
+	if jid.Valid {
+		parsed, err := types.ParseJID(jid.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device of session %s: %v", name, err)
+		}
+
+		if device, err = m.container.GetDevice(parsed); err != nil {
+			return nil, err
+		}
+	}
+
+	// not paired yet, or the device was removed from the phone
+	if device == nil {
+		device = m.container.NewDevice()
+	}
+
+	return newSession(m, name, device)
+}
+
+// forget removes the loaded client of the session, a load still running is dropped when it finishes
+func (m *Manager) forget(name string) (*Session, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if l, ok := m.loading[name]; ok {
+		l.forgotten = true
+	}
+
+	wa, loaded := m.sessions[name]
+	delete(m.sessions, name)
+
+	return wa, loaded
+}
+
+// Create adds a new session and starts its QR login
+func (m *Manager) Create(ctx context.Context, name string) (*Session, error) {
+	if !sessionNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid session name, use 1 to 32 letters, numbers, - or _")
+	}
+
+	_, err := m.db.ExecContext(ctx, `INSERT INTO wa_sessions (name) VALUES ($1)`, name)
+	var pq_err *pq.Error
+	if errors.As(err, &pq_err) && pq_err.Code == "23505" {
+		return nil, fmt.Errorf("%w: %s", ErrSessionExists, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Get(name)
+}
+
+// Info returns the stored data of the session with the live state of its client when it is loaded
+func (m *Manager) Info(ctx context.Context, name string) (SessionInfo, error) {
+	info := SessionInfo{Name: name}
+	err := m.db.QueryRowContext(ctx, `SELECT COALESCE(jid, ''), created_at FROM wa_sessions WHERE name = $1`, name).
+		Scan(&info.JID, &info.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SessionInfo{}, fmt.Errorf("%w: %s", ErrSessionNotFound, name)
+	}
+	if err != nil {
+		return SessionInfo{}, err
+	}
+
+	m.fillState(&info)
+
+	return info, nil
+}
+
+// List returns every session ordered by name
+func (m *Manager) List(ctx context.Context) ([]SessionInfo, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT name, COALESCE(jid, ''), created_at FROM wa_sessions ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []SessionInfo{}
+	for rows.Next() {
+		var info SessionInfo
+		if err := rows.Scan(&info.Name, &info.JID, &info.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		m.fillState(&info)
+		sessions = append(sessions, info)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (m *Manager) fillState(info *SessionInfo) {
+	m.mu.Lock()
+	wa, ok := m.sessions[info.Name]
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	info.IsConnected = wa.client.IsConnected()
+	info.IsLoggedIn = wa.client.Store.ID != nil
+	_, info.QRReady = wa.GetQRCode()
+}
+
+// Delete logs the session out, removes its device and forgets the session. The default session can only be logged out
+func (m *Manager) Delete(ctx context.Context, name string) error {
+	if name == DefaultSession {
+		return fmt.Errorf("the %s session can not be deleted, log it out instead", DefaultSession)
+	}
+
+	info, err := m.Info(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	wa, loaded := m.forget(name)
+
+	switch {
+	case loaded && wa.client.Store.ID != nil && wa.client.IsConnected():
+		// logout also removes the device from the store
+		if err := wa.client.Logout(); err != nil {
+			return fmt.Errorf("error during logout: %v", err)
+		}
+	case info.JID != "":
+		jid, err := types.ParseJID(info.JID)
+		if err == nil {
+			if err := m.container.DeleteDevice(&store.Device{ID: &jid}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if loaded {
+		wa.client.Disconnect()
+	}
+
+	_, err = m.db.ExecContext(ctx, `DELETE FROM wa_sessions WHERE name = $1`, name)
+	return err
+}
+
+// setJID saves the device the session is paired with
+func (m *Manager) setJID(name string, jid *types.JID) error {
+	if jid == nil {
+		return nil
+	}
+
+	_, err := m.db.Exec(`UPDATE wa_sessions SET jid = $2 WHERE name = $1`, name, jid.String())
+	return err
+}
+
+// reset forgets the client and the device of a logged out session, the next Get starts a new QR login
+func (m *Manager) reset(name string) {
+	m.forget(name)
+
+	if _, err := m.db.Exec(`UPDATE wa_sessions SET jid = NULL WHERE name = $1`, name); err != nil {
+		log.Printf("Failed to reset session %s: %s\n", name, err.Error())
+	}
+}
@@ -4,90 +4,52 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"sync"
+	"time"
 
-	_ "github.com/lib/pq" // Import PostgreSQL driver
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/proto/waE2E"
-	"go.mau.fi/whatsmeow/store/sqlstore"
+	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/types"
 	waLog "go.mau.fi/whatsmeow/util/log"
 	"google.golang.org/protobuf/proto"
 	_ "modernc.org/sqlite" // Import SQLite driver and with this can use SQLite without cgo enabled = 1
 )
 
-type whatsApp struct {
+// Session is one WhatsApp account, sessions are created and loaded by the Manager
+type Session struct {
+	name    string
+	manager *Manager
 	client  *whatsmeow.Client
 	qrCode  string
 	qrReady bool
 	mutex   sync.RWMutex
-}
-
-// Singleton instance
-var (
-	instance *whatsApp
-	once     sync.Once
-	initErr  error
-	mutex    sync.Mutex // Mutex to protect instance reset
-)
-
-// ResetInstance clears the singleton instance
-func ResetInstance() {
-	mutex.Lock()
-	defer mutex.Unlock()
-	instance = nil
-	once = sync.Once{}
-}
-
-// NewWhatsApp returns a singleton WhatsApp instance
-func NewWhatsApp() (*whatsApp, error) {
-	mutex.Lock()
-	defer mutex.Unlock()
+	limiter *limiter
+	uploads *uploadCache
 
-	if instance == nil {
-		instance, initErr = initWhatsApp()
-	}
-	return instance, initErr
+	presenceMu sync.Mutex
+	idleTimer  *time.Timer // set while the session is shown available after typed messages
 }
 
-// initWhatsApp creates a new WhatsApp client instance
-func initWhatsApp() (*whatsApp, error) {
-	pgHost := os.Getenv("HOST_POSTGRES")
-	pgPort := os.Getenv("PORT_POSTGRES")
-	pgUser := os.Getenv("USER_POSTGRES")
-	pgPassword := os.Getenv("PASSWORD_POSTGRES")
-	pgDatabase := os.Getenv("DATABASE_POSTGRES")
-
-	pgConnString := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		pgHost, pgPort, pgUser, pgPassword, pgDatabase)
-
-	// Initialize with PostgreSQL instead of SQLite
-	container, err := sqlstore.New("postgres", pgConnString, waLog.Noop)
-
-	// using SQLite instead of PostgreSQL
-	// container, err := sqlstore.New("sqlite", "file:wapp.db?_pragma=foreign_keys(1)", waLog.Noop)
-
-	if err != nil {
-		return nil, err
-	}
-
-	deviceStore, err := container.GetFirstDevice()
-	if err != nil {
-		return nil, err
-	}
-
-	wa := &whatsApp{
-		client:  whatsmeow.NewClient(deviceStore, waLog.Noop),
+// newSession creates the client of the device and connects it,
+// a device that is not paired yet starts the QR login
+func newSession(manager *Manager, name string, device *store.Device) (*Session, error) {
+	wa := &Session{
+		name:    name,
+		manager: manager,
+		client:  whatsmeow.NewClient(device, waLog.Noop),
 		qrCode:  "",
 		qrReady: false,
 		mutex:   sync.RWMutex{},
+		limiter: newLimiter(manager.limits),
+		uploads: newUploadCache(),
 	}
+	wa.client.AddEventHandler(wa.handleEvent)
 
 	if wa.client.Store.ID == nil {
 		// No ID stored, new login
 		qrChan, _ := wa.client.GetQRChannel(context.Background())
-		err = wa.client.Connect()
+		err := wa.client.Connect()
 		if err != nil {
 			return nil, err
 		}
@@ -103,6 +65,8 @@ func initWhatsApp() (*whatsApp, error) {
 					wa.qrReady = true
 					wa.mutex.Unlock()
 
+					wa.emit(EventQR, QREvent{Code: evt.Code})
+
 					// Log this important transition
 					// log.Println("QR code is now ready for scanning")
 				} else {
@@ -110,7 +74,11 @@ func initWhatsApp() (*whatsApp, error) {
 						wa.mutex.Lock()
 						wa.qrReady = false
 						wa.mutex.Unlock()
-						// log.Println("Login successful, QR code no longer needed")
+
+						// remember which device belongs to the session so it is loaded again after a restart
+						if err := manager.setJID(name, wa.client.Store.ID); err != nil {
+							log.Printf("Failed to save device of session %s: %s\n", name, err.Error())
+						}
 					}
 				}
 			}
@@ -126,12 +94,17 @@ func initWhatsApp() (*whatsApp, error) {
 	return wa, nil
 }
 
-func (w *whatsApp) GetClient() *whatsmeow.Client {
+// Name returns the name of the session
+func (w *Session) Name() string {
+	return w.name
+}
+
+func (w *Session) GetClient() *whatsmeow.Client {
 	return w.client
 }
 
 // Ensure connection is active and recover if needed
-func (w *whatsApp) ensureConnected() error {
+func (w *Session) ensureConnected() error {
 	w.mutex.RLock()
 	isConnected := w.client != nil && w.client.IsConnected() && w.client.Store.ID != nil
 	w.mutex.RUnlock()
@@ -149,42 +122,87 @@ func (w *whatsApp) ensureConnected() error {
 	return nil
 }
 
-func (w *whatsApp) SendMessage(to, message string, with_disconnect bool) error {
-	if w.client == nil {
-		return fmt.Errorf("client is nil")
+// SendMessage sends a text message to the given number or group JID and returns the server response,
+// which holds the WhatsApp message ID and the server timestamp of the sent message
+func (w *Session) SendMessage(to, message string, opts SendOptions) (whatsmeow.SendResponse, error) {
+	return w.send(to, &waE2E.Message{
+		Conversation: proto.String(message),
+	}, opts)
+}
+
+// send delivers any kind of message to the given number or group after making sure the client is connected.
+// The rate limiter of the session is checked first, when it does not allow the message yet nothing is sent
+// and a *RateLimitError tells when to try again. With typing the chat shows the account typing before the message arrives
+func (w *Session) send(to string, message *waE2E.Message, opts SendOptions) (whatsmeow.SendResponse, error) {
+	jid, err := w.prepare(to)
+	if err != nil {
+		return whatsmeow.SendResponse{}, err
 	}
 
-	if w.client.Store.ID == nil {
-		return fmt.Errorf("client is not connected")
+	return w.deliver(jid, message, opts)
+}
+
+// prepare checks the recipient and the session and takes a slot of the limiter for the recipient.
+// Media is only uploaded after this, so a message deferred by the limiter uploads nothing
+func (w *Session) prepare(to string) (types.JID, error) {
+	jid, err := ParseRecipient(to)
+	if err != nil {
+		return types.JID{}, err
 	}
 
-	// Ensure we're connected before sending
-	if err := w.ensureConnected(); err != nil {
-		return fmt.Errorf("connection check failed: %v", err)
+	if err := w.checkReady(); err != nil {
+		return types.JID{}, err
+	}
+
+	if wait := w.limiter.reserve(jid.String()); wait > 0 {
+		return types.JID{}, &RateLimitError{RetryAfter: wait}
+	}
+	time.Sleep(w.limiter.jitter())
+
+	return jid, nil
+}
+
+// deliver sends the message to a recipient returned by prepare
+func (w *Session) deliver(jid types.JID, message *waE2E.Message, opts SendOptions) (whatsmeow.SendResponse, error) {
+	if opts.Typing {
+		w.simulateTyping(jid, message)
 	}
 
 	ctx := context.Background()
-	_, err := w.client.SendMessage(ctx, types.JID{
-		User:   to,
-		Server: types.DefaultUserServer,
-	}, &waE2E.Message{
-		Conversation: proto.String(message),
-	})
+	resp, err := w.client.SendMessage(ctx, jid, message)
 	if err != nil {
-		return fmt.Errorf("error sending message: %v", err)
+		return whatsmeow.SendResponse{}, fmt.Errorf("error sending message: %v", err)
 	}
 
 	// IMPORTANT: Only disconnect if explicitly requested
-	// This should rarely be used - the singleton pattern means we usually want
+	// This should rarely be used - sessions are shared so we usually want
 	// to keep the connection alive
-	if with_disconnect {
+	if opts.Disconnect {
 		w.client.Disconnect()
 	}
 
+	return resp, nil
+}
+
+// checkReady makes sure the client is logged in and connected before talking to WhatsApp
+func (w *Session) checkReady() error {
+	if w.client == nil {
+		return fmt.Errorf("client is nil")
+	}
+
+	if w.client.Store.ID == nil {
+		return fmt.Errorf("client is not connected")
+	}
+
+	// Ensure we're connected before sending
+	if err := w.ensureConnected(); err != nil {
+		return fmt.Errorf("connection check failed: %v", err)
+	}
+
 	return nil
 }
 
-func (w *whatsApp) Disconnect() error {
+func (w *Session) Disconnect() error {
 	if w.client == nil {
 		return fmt.Errorf("client is nil")
 	}
@@ -194,7 +212,7 @@ func (w *whatsApp) Disconnect() error {
 	return nil
 }
 
-func (w *whatsApp) Logout() error {
+func (w *Session) Logout() error {
 	if w.client == nil {
 		return fmt.Errorf("client is nil")
 	}
@@ -217,14 +235,17 @@ func (w *whatsApp) Logout() error {
 	// Disconnect after logout
 	w.client.Disconnect()
 
-	// Reset the singleton instance to force reinitialization on next call
-	ResetInstance()
+	// whatsmeow only reports logouts made from the phone, tell the handlers about this one too
+	w.emit(EventLoggedOut, LoggedOutEvent{Reason: "logout"})
+
+	// Drop the session client so the next call starts a new QR login
+	w.manager.reset(w.name)
 
 	return nil
 }
 
 // GetQRCode returns the current QR code and a boolean indicating if it's ready
-func (w *whatsApp) GetQRCode() (string, bool) {
+func (w *Session) GetQRCode() (string, bool) {
 	// Thread-safe read of QR code
 	w.mutex.RLock()
 	defer w.mutex.RUnlock()
@@ -233,7 +254,7 @@ func (w *whatsApp) GetQRCode() (string, bool) {
 }
 
 // IsConnected checks if the client is currently connected
-func (w *whatsApp) IsConnected() bool {
+func (w *Session) IsConnected() bool {
 	if w.client == nil {
 		return false
 	}